@@ -0,0 +1,84 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+)
+
+// Values of --input-format.
+const (
+	inputFormatJSONArray = "json-array"
+	inputFormatJSONL     = "jsonl"
+)
+
+// ----------------------------------------------------------------------------
+
+// Validate the elements of a stream holding a JSON array and report them.
+func validateArray(source string, reader io.Reader) {
+	result := newSummary(source)
+	validateJSONArray(skipBOM(reader), result)
+	result.report()
+	inputProgress = nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate each element of a top-level JSON array as a record, numbered by
+// its position in the array.  Elements are decoded one at a time so memory
+// stays bounded by the largest record.  Anything but whitespace after the
+// array is an input error.
+func validateJSONArray(reader io.Reader, result *summary) {
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('[') {
+		if err == nil {
+			err = fmt.Errorf("found %v", token)
+		}
+		arrayError(result, "the input does not start with a JSON array", err)
+		return
+	}
+	checks := newLineChecks(result)
+	checks.elements = "Element"
+	var line bytes.Buffer
+	for decoder.More() {
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			arrayError(result, fmt.Sprintf("element %d is not valid JSON", result.lines+1), err)
+			return
+		}
+		// elements may span lines, compact them into a JSON-line
+		line.Reset()
+		json.Compact(&line, element)
+		if !validateLine(checks, result, line.Bytes()) {
+			return
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		arrayError(result, "the JSON array is not closed", err)
+		return
+	}
+	if token, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("found %v", token)
+		}
+		arrayError(result, "there is more data after the JSON array", err)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Report a JSON array that can't be decoded any further.  The elements
+// validated so far are kept.
+func arrayError(result *summary, problem string, err error) {
+	result.Incomplete = true
+	logger.LogMessageFromError(MessageIdFormat, 2013, "Error decoding the JSON array input, "+problem+".", err)
+	output.Println("Stopped reading", result.Source+",", problem+":", err)
+	raiseStatus(statusInputError)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// Scan the input as the validate command does, returning its output and the
+// run status it raised.
+func scanInput(t *testing.T, options map[string]interface{}, input string) (string, exitStatus) {
+	t.Helper()
+	useOptions(t, options)
+	buffer := captureOutput(t)
+	saved := runStatus
+	runStatus = statusClean
+	t.Cleanup(func() { runStatus = saved })
+	if err := scanLines("test", strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	output.Flush()
+	return buffer.String(), runStatus
+}
+
+// ----------------------------------------------------------------------------
+
+// An input starting with [ is only read as a JSON array when the
+// --input-format isn't given.
+func TestInputFormatSniffing(t *testing.T) {
+	input := `[{"DATA_SOURCE":"TEST","RECORD_ID":"1"}, {"DATA_SOURCE":"TEST","RECORD_ID":"2"}]` + "\n"
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    string
+	}{
+		{"sniffed", nil, "Validated 2 lines, 0 were bad."},
+		{"json-array", map[string]interface{}{InputFormat: inputFormatJSONArray}, "Validated 2 lines, 0 were bad."},
+		{"jsonl", map[string]interface{}{InputFormat: inputFormatJSONL}, "Validated 1 lines, 1 were bad."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, _ := scanInput(t, test.options, input)
+			if !strings.Contains(text, test.want) {
+				t.Errorf("the output has no %q:\n%s", test.want, text)
+			}
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Data after the closing ] of a JSON array is an input error, whitespace is
+// not.
+func TestJSONArrayTrailingData(t *testing.T) {
+	array := `[{"DATA_SOURCE":"TEST","RECORD_ID":"1"}]`
+	tests := []struct {
+		name     string
+		trailing string
+		status   exitStatus
+	}{
+		{"whitespace", "\n \n", statusClean},
+		{"record", "\n" + `{"DATA_SOURCE":"TEST","RECORD_ID":"2"}` + "\n", statusInputError},
+		{"array", `[]`, statusInputError},
+		{"bracket", "]", statusInputError},
+		{"text", "trailing", statusInputError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, status := scanInput(t, map[string]interface{}{InputFormat: inputFormatJSONArray}, array+test.trailing)
+			if status != test.status {
+				t.Errorf("the status is %d, want %d:\n%s", status, test.status, text)
+			}
+			if reported := strings.Contains(text, "there is more data after the JSON array"); reported != (test.status != statusClean) {
+				t.Errorf("more data reported is %v:\n%s", reported, text)
+			}
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// A file starting with [ is read as JSONL when --input-format jsonl is given.
+func TestInputFormatFile(t *testing.T) {
+	path := t.TempDir() + "/records.json"
+	if err := os.WriteFile(path, []byte(`[{"DATA_SOURCE":"TEST","RECORD_ID":"1"}, {"DATA_SOURCE":"TEST","RECORD_ID":"2"}]`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for format, want := range map[string]string{"": "Validated 2 lines, 0 were bad.", inputFormatJSONL: "Validated 1 lines, 1 were bad."} {
+		options := map[string]interface{}{}
+		if len(format) > 0 {
+			options[InputFormat] = format
+		}
+		useOptions(t, options)
+		buffer := captureOutput(t)
+		readFile(path, "")
+		output.Flush()
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("--input-format %q: the output has no %q:\n%s", format, want, buffer.String())
+		}
+	}
+}
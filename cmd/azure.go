@@ -0,0 +1,105 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/senzing/senzing-tools/option"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Stream an az://container/blob object into the validator.  With
+// AZURE_STORAGE_CONNECTION_STRING set, e.g. for the Azurite emulator, the
+// connection string is used.  Otherwise the blob is read from the
+// --azure-storage-account account with the default Azure credential chain:
+// environment, workload or managed identity, then the Azure CLI.  The blob
+// type follows --input-file-type or the name's suffix, so gzipped blobs are
+// decompressed.  With --state-file a blob whose ETag already validated
+// cleanly is skipped.
+func readAzureBlob(u *url.URL) bool {
+	container := u.Host
+	blob := strings.TrimPrefix(u.Path, "/")
+	if len(container) == 0 || len(blob) == 0 {
+		logger.LogMessage(MessageIdFormat, 9046, fmt.Sprintf("Fatal error, an Azure inputURL needs a container and a blob: %s", u.String()))
+		output.Println("An Azure inputURL looks like az://container/path/file.jsonl")
+		return false
+	}
+	client, err := newAzureClient()
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9047, "Fatal error creating the Azure Blob Storage client.", err)
+		output.Println("Unable to create the Azure Blob Storage client:", err)
+		return false
+	}
+	object, err := client.DownloadStream(runContext, container, blob, nil)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9048, "Fatal error retrieving the Azure blob.", err)
+		output.Println("Unable to get", u.String()+":", err)
+		return false
+	}
+	defer object.Body.Close()
+	return readUnlessStored(u, func() string { return azureETag(object.ETag) }, func() bool {
+		return readAzureBody(u, blob, object)
+	})
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the body of an Azure blob.
+func readAzureBody(u *url.URL, blob string, object azblob.DownloadStreamResponse) bool {
+	size := int64(-1)
+	if object.ContentLength != nil {
+		size = *object.ContentLength
+	}
+	body := &countingReader{reader: object.Body}
+	reader := bufio.NewReader(trackProgress(body, size))
+	source := u.String()
+	if !validateTypedStream(source, streamType(blob, viper.GetString(option.InputFileType), reader), reader) {
+		return false
+	}
+	return checkSize(size, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// The ETag of a blob, empty when it has none.
+func azureETag(etag *azcore.ETag) string {
+	if etag == nil {
+		return ""
+	}
+	return string(*etag)
+}
+
+// ----------------------------------------------------------------------------
+
+// The Blob Storage client, from a connection string or the storage account
+// and the default Azure credential chain.
+func newAzureClient() (*azblob.Client, error) {
+	if connectionString := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); len(connectionString) > 0 {
+		return azblob.NewClientFromConnectionString(connectionString, nil)
+	}
+	account := viper.GetString(AzureStorageAccount)
+	if len(account) == 0 {
+		account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	if len(account) == 0 {
+		return nil, errors.New("no storage account, use --" + AzureStorageAccount + " or AZURE_STORAGE_ACCOUNT")
+	}
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	return azblob.NewClient("https://"+account+".blob.core.windows.net/", credential, nil)
+}
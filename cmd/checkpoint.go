@@ -0,0 +1,150 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/roncewind/validate/jsonl"
+	"github.com/spf13/viper"
+)
+
+// How many lines are validated between writes of the --checkpoint.
+const checkpointInterval = 10000
+
+// checkpoint is the progress through an input recorded in --checkpoint.
+type checkpoint struct {
+	Source string `json:"source"`
+	// the lines read, including blank ones
+	Lines int `json:"lines"`
+	// the byte offset of the next line, -1 when it isn't known
+	Offset int64 `json:"offset"`
+	// the JSON summary of the lines read
+	Summary json.RawMessage `json:"summary"`
+	// the input was opened at Offset, so no lines need skipping
+	seeked bool
+}
+
+// The checkpoint to resume from with --resume, nil once it has been used or
+// when starting over.
+var resumePoint *checkpoint
+
+// ----------------------------------------------------------------------------
+
+// Load the --checkpoint for --resume.  Without a checkpoint file there is
+// nothing to resume, and the input is validated from the start.
+func openCheckpoint() bool {
+	resumePoint = nil
+	path := viper.GetString(Checkpoint)
+	if !viper.GetBool(Resume) {
+		return true
+	}
+	if len(path) == 0 {
+		logger.LogMessage(MessageIdFormat, 9053, fmt.Sprintf("Fatal error, --%s needs a --%s file.", Resume, Checkpoint))
+		output.Println("--"+Resume, "needs a --"+Checkpoint, "file.")
+		return false
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.LogMessage(MessageIdFormat, 50, "No checkpoint to resume from, starting from the beginning.")
+		return true
+	}
+	resumePoint = &checkpoint{}
+	if err == nil {
+		err = json.Unmarshal(content, resumePoint)
+	}
+	if err != nil {
+		resumePoint = nil
+		logger.LogMessageFromError(MessageIdFormat, 9054, "Fatal error reading the checkpoint.", err)
+		output.Println("Unable to read the checkpoint:", err)
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The checkpoint to resume source from, nil when there is none.  It is only
+// used once.
+func resumeFrom(source string) *checkpoint {
+	if resumePoint == nil || resumePoint.Source != redactURL(source) {
+		return nil
+	}
+	resume := resumePoint
+	resumePoint = nil
+	return resume
+}
+
+// ----------------------------------------------------------------------------
+
+// Open a local JSONL file at the offset of the checkpoint to resume it from,
+// so the lines before it needn't be read again.
+func seekToCheckpoint(file *os.File, source string) {
+	if resumePoint == nil || resumePoint.Source != redactURL(source) || resumePoint.Offset <= 0 {
+		return
+	}
+	if _, err := file.Seek(resumePoint.Offset, 0); err == nil {
+		resumePoint.seeked = true
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Restore the summary of the lines read before the checkpoint, and skip
+// them unless the input was opened at the checkpoint's offset.
+func (cp *checkpoint) restore(result *summary, splitter *jsonl.Splitter) {
+	source := result.Source
+	if err := json.Unmarshal(cp.Summary, result); err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2016, "Error restoring the checkpoint summary, the counts start over.", err)
+	}
+	result.Source = source
+	result.lines = cp.Lines
+	if cp.seeked {
+		splitter.Resume(cp.Offset)
+	} else {
+		result.skipLines = cp.Lines
+	}
+	logger.LogMessage(MessageIdFormat, 51, fmt.Sprintf("Resuming %s after line %d.", redactURL(source), cp.Lines))
+	output.Println("Resuming after line", cp.Lines)
+}
+
+// ----------------------------------------------------------------------------
+
+// Record the progress through an input in --checkpoint every
+// checkpointInterval lines.
+func saveCheckpoint(result *summary, line *lineResult) {
+	if line.number%checkpointInterval != 0 || len(line.element) > 0 {
+		return
+	}
+	path := viper.GetString(Checkpoint)
+	if len(path) == 0 {
+		return
+	}
+	report, err := result.jsonReport()
+	if err == nil {
+		var content []byte
+		content, err = json.Marshal(checkpoint{Source: redactURL(result.Source), Lines: line.number, Offset: line.next, Summary: report})
+		if err == nil {
+			err = replaceFile(path, append(content, '\n'))
+		}
+	}
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2017, "Error writing the checkpoint.", err)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Remove the --checkpoint once an input has been read to its end.
+func clearCheckpoint() {
+	if path := viper.GetString(Checkpoint); len(path) > 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.LogMessageFromError(MessageIdFormat, 2017, "Error removing the checkpoint.", err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"github.com/roncewind/validate/jsonl"
+	"github.com/senzing/go-common/record"
+)
+
+// probe is a record with a known problem and the category it should map to.
+type probe struct {
+	description string
+	line        string
+	expected    string // empty when the record is valid
+}
+
+// Records used by --debug-classification to show how record.Validate errors
+// are bucketed with the current go-common version.
+var probes = []probe{
+	{"valid record", `{"DATA_SOURCE":"TEST","RECORD_ID":"1"}`, ""},
+	{"missing RECORD_ID", `{"DATA_SOURCE":"TEST"}`, categoryNoRecordId},
+	{"missing DATA_SOURCE", `{"RECORD_ID":"1"}`, categoryNoDataSource},
+	{"missing both fields", `{}`, categoryNoDataSource},
+	{"truncated JSON", `{"DATA_SOURCE":"TEST",`, categoryMalformed},
+	{"JSON array", `[]`, categoryMalformed},
+	{"numeric RECORD_ID", `{"DATA_SOURCE":"TEST","RECORD_ID":1}`, categoryMalformed},
+	{"null RECORD_ID", `{"DATA_SOURCE":"TEST","RECORD_ID":null}`, categoryNoRecordId},
+	{"empty DATA_SOURCE", `{"DATA_SOURCE":"","RECORD_ID":"1"}`, categoryNoDataSource},
+}
+
+// ----------------------------------------------------------------------------
+
+// Run record.Validate on every probe and print the category its error maps
+// to, flagging any that differ from what's expected.  Returns false on a
+// mismatch.
+func debugClassification() bool {
+	matched := true
+	output.Println("Classification of record.Validate errors:")
+	for _, p := range probes {
+		category := ""
+		message := "valid"
+		if valid, err := record.Validate(p.line); !valid {
+			category = categoryBadRecord
+			if err != nil {
+				category = jsonl.ClassifyError(err)
+				message = err.Error()
+			} else {
+				message = "invalid without an error"
+			}
+		}
+		status := "ok"
+		if category != p.expected {
+			status = "MISMATCH, expected " + categoryName(p.expected)
+			matched = false
+		}
+		output.Printf("  %-20s -> %-14s %s (%s)\n", p.description, categoryName(category), status, message)
+	}
+	return matched
+}
+
+// ----------------------------------------------------------------------------
+
+func categoryName(category string) string {
+	if len(category) == 0 {
+		return "valid"
+	}
+	return category
+}
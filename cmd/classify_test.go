@@ -0,0 +1,30 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/roncewind/validate/jsonl"
+)
+
+// ----------------------------------------------------------------------------
+
+// The --debug-classification probes get their expected categories from the
+// line checks, through the record package errors.
+func TestProbeCategories(t *testing.T) {
+	useOptions(t, nil)
+	for _, p := range probes {
+		line := &lineResult{number: 1, raw: []byte(p.line), line: p.line}
+		(&lineChecks{checker: jsonl.NewChecker(recordOptions(nil))}).validate(line)
+		// an empty DATA_SOURCE is told apart from a missing one
+		want := p.expected
+		if p.description == "empty DATA_SOURCE" {
+			want = categoryEmptyDataSource
+		}
+		if line.category != want {
+			t.Errorf("%s: %s is %s, want %s (%s)", p.description, p.line, categoryName(line.category), categoryName(want), line.message)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// Values of --color.
+const (
+	colorAlways = "always"
+	colorAuto   = "auto"
+	colorNever  = "never"
+)
+
+// ANSI escape sequences for the colors used.
+const (
+	ansiGreen = "\x1b[32m"
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+// Whether the output is colored, see setColor.
+var useColor bool
+
+// ----------------------------------------------------------------------------
+
+// Decide from --color whether to color the output.  With auto it is colored
+// only when written to a terminal and NO_COLOR isn't set.  Returns false for
+// an unknown value.
+func setColor() bool {
+	switch viper.GetString(Color) {
+	case colorAlways:
+		useColor = true
+	case colorNever:
+		useColor = false
+	case colorAuto:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		useColor = !noColor && term.IsTerminal(int(outputFile().Fd()))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown --%s %s, use %s, %s or %s.\n", Color, viper.GetString(Color), colorAuto, colorAlways, colorNever)
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The file the output is written to, following the choices made in Run.
+func outputFile() *os.File {
+	if logFile != nil {
+		return logFile
+	}
+	switch viper.GetString(ReportFormat) {
+	case reportFormatJSON, reportFormatCSV:
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// ----------------------------------------------------------------------------
+
+// The text in color, when the output is colored.
+func colored(color string, text string) string {
+	if !useColor {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// ----------------------------------------------------------------------------
+
+// A summary line in green when nothing was bad, else in red.
+func passFail(bad int, text string) string {
+	if bad > 0 {
+		return colored(ansiRed, text)
+	}
+	return colored(ansiGreen, text)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/spf13/viper"
+)
+
+// Streaming decompressors by file type.  Gzip has its own readers.
+var decompressors = map[string]func(io.Reader) (io.Reader, error){
+	"BZ2": func(reader io.Reader) (io.Reader, error) { return bzip2.NewReader(reader), nil },
+	"LZ4": func(reader io.Reader) (io.Reader, error) { return lz4.NewReader(reader), nil },
+	"ZST": newZstdReader,
+}
+
+// The size of the blocks --parallel-gzip decompresses ahead.
+const gzipBlockSize = 1 << 20
+
+// ----------------------------------------------------------------------------
+
+// opens and reads a JSONL file compressed with one of the decompressors
+func readCompressedFile(compressedFile string, fileType string) bool {
+	file, err := os.Open(compressedFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9020, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer file.Close()
+	reader, err := decompressors[fileType](trackProgress(file, fileSize(file)))
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9039, "Fatal error reading inputURL.", err)
+		return false
+	}
+	return validateCompressed(compressedFile, fileType, reader)
+}
+
+// ----------------------------------------------------------------------------
+
+// retrieves and reads a JSONL resource compressed with one of the
+// decompressors
+func readCompressedResource(compressedURL string, fileType string) bool {
+	response, err := getResource(compressedURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9021, "Fatal error retrieving inputURL.", err)
+		return false
+	}
+	defer response.Body.Close()
+	body := &countingReader{reader: response.Body}
+	reader, err := decompressors[fileType](trackProgress(body, response.ContentLength))
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9039, "Fatal error reading inputURL.", err)
+		return false
+	}
+	validateCompressed(compressedURL, fileType, reader)
+	return checkContentLength(response, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// A zstd decoder that decodes in the calling goroutine, so it holds no
+// background goroutines and needs no Close.
+func newZstdReader(reader io.Reader) (io.Reader, error) {
+	return zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+}
+
+// ----------------------------------------------------------------------------
+
+// A gzip reader that, with --gzip-multistream, reads on through every member
+// of concatenated gzip streams, or else stops at the end of the first.  With
+// --parallel-gzip the input is decompressed by pgzip, ahead of the validation
+// in other goroutines, otherwise by the standard library reader.
+func newGzipReader(reader io.Reader) (io.ReadCloser, error) {
+	if blocks := viper.GetInt(ParallelGzip); blocks > 0 {
+		return newParallelGzipReader(reader, blocks, viper.GetBool(GzipMultistream))
+	}
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	gzipReader.Multistream(viper.GetBool(GzipMultistream))
+	return gzipReader, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// A gzip input reads the same with and without --parallel-gzip, and a
+// truncated one still ends with io.ErrUnexpectedEOF, also when it is cut
+// just before its trailer, which pgzip alone reads as a clean end.
+func TestParallelGzip(t *testing.T) {
+	text := strings.Repeat(`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}`+"\n", 100000)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(text))
+	writer.Close()
+	truncations := [][]byte{compressed.Bytes()[:compressed.Len()/2], compressed.Bytes()[:compressed.Len()-8]}
+
+	for _, blocks := range []int{0, 1, 4} {
+		useOptions(t, map[string]interface{}{ParallelGzip: blocks, GzipMultistream: true})
+		reader, err := newGzipReader(bytes.NewReader(compressed.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil || string(read) != text {
+			t.Errorf("%d blocks read %d of %d bytes: %v", blocks, len(read), len(text), err)
+		}
+
+		for _, truncated := range truncations {
+			reader, err = newGzipReader(bytes.NewReader(truncated))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.ReadAll(reader)
+			reader.Close()
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("%d blocks read %d of %d bytes with %v, want %v", blocks, len(truncated), compressed.Len(), err, io.ErrUnexpectedEOF)
+			}
+		}
+	}
+
+	// every member of concatenated streams, or only the first
+	concatenated := append(bytes.Clone(compressed.Bytes()), compressed.Bytes()...)
+	for _, multistream := range []bool{true, false} {
+		useOptions(t, map[string]interface{}{ParallelGzip: 4, GzipMultistream: multistream})
+		reader, err := newGzipReader(bytes.NewReader(concatenated))
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := io.ReadAll(reader)
+		reader.Close()
+		want := text
+		if multistream {
+			want += text
+		}
+		if err != nil || string(read) != want {
+			t.Errorf("--gzip-multistream %v read %d of %d bytes: %v", multistream, len(read), len(want), err)
+		}
+	}
+}
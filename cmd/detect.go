@@ -0,0 +1,251 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+)
+
+// Query parameters that download style URLs use to carry the file name.
+var fileNameParameters = []string{"file", "filename", "name"}
+
+// Recognized file name suffixes and the file types they imply.
+var fileTypeSuffixes = map[string]string{
+	"jsonl":   "JSONL",
+	"json":    "JSON",
+	"gz":      "GZ",
+	"zip":     "ZIP",
+	"lz4":     "LZ4",
+	"bz2":     "BZ2",
+	"zst":     "ZST",
+	"tar":     "TAR",
+	"parquet": "PARQUET",
+}
+
+// The file types implied by the media type of a response's Content-Type.
+var contentTypes = map[string]string{
+	"application/gzip":               "GZ",
+	"application/x-gzip":             "GZ",
+	"application/zip":                "ZIP",
+	"application/x-zip-compressed":   "ZIP",
+	"application/x-lz4":              "LZ4",
+	"application/x-bzip2":            "BZ2",
+	"application/zstd":               "ZST",
+	"application/x-tar":              "TAR",
+	"application/vnd.apache.parquet": "PARQUET",
+	"application/x-parquet":          "PARQUET",
+	"application/jsonl":              "JSONL",
+	"application/x-jsonlines":        "JSONL",
+	"application/x-ndjson":           "JSONL",
+}
+
+// The file types implied by a response's Content-Encoding.  Go only
+// decompresses the encodings it asked for, so these arrive compressed.
+var contentEncodings = map[string]string{
+	"gzip":   "GZ",
+	"x-gzip": "GZ",
+	"zstd":   "ZST",
+}
+
+// ----------------------------------------------------------------------------
+
+// The file type implied by the suffix of a file name, empty when unknown.
+func fileTypeOf(name string) string {
+	for suffix, fileType := range fileTypeSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return fileType
+		}
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+
+// The name used to detect the type of a resource.  When the path has no
+// recognized suffix, a file name carried in the query is used instead, as in
+// "https://host/download?file=data.jsonl".
+func resourceName(u *url.URL) string {
+	if len(fileTypeOf(u.Path)) > 0 {
+		return u.Path
+	}
+	query := u.Query()
+	for _, parameter := range fileNameParameters {
+		if name := query.Get(parameter); len(fileTypeOf(name)) > 0 {
+			return name
+		}
+	}
+	return u.Path
+}
+
+// ----------------------------------------------------------------------------
+
+// Fetch a resource and detect its type: a guess from the leading bytes, else
+// the suffix of its name, else the Content-Disposition file name, else the
+// Content-Encoding or Content-Type.
+func readDetectedResource(resourceURL string, name string) bool {
+	response, err := getResource(resourceURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9018, "Fatal error retrieving inputURL.", err)
+		return false
+	}
+	defer response.Body.Close()
+	body := &countingReader{reader: response.Body}
+	reader := bufio.NewReader(trackProgress(body, response.ContentLength))
+
+	resourceType := sniffFileType(reader)
+	if len(resourceType) == 0 {
+		resourceType = fileTypeOf(name)
+	}
+	if len(resourceType) == 0 {
+		resourceType = contentDispositionType(response)
+	}
+	if len(resourceType) == 0 {
+		resourceType = contentHeaderType(response)
+	}
+	if !validateTypedStream(resourceURL, resourceType, reader) {
+		return false
+	}
+	return checkContentLength(response, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// The type of a stream: the --input-file-type override, else a guess from the
+// leading bytes, else the type implied by the name's suffix.
+func streamType(name string, fileType string, reader *bufio.Reader) string {
+	if len(fileType) > 0 {
+		return strings.ToUpper(fileType)
+	}
+	if streamType := sniffFileType(reader); len(streamType) > 0 {
+		return streamType
+	}
+	return fileTypeOf(name)
+}
+
+// ----------------------------------------------------------------------------
+
+// Decompress a stream of the given type as needed and validate its lines.
+// Returns false when the type isn't one that can be streamed.
+func validateTypedStream(source string, streamType string, reader *bufio.Reader) bool {
+	switch streamType {
+	case "JSONL":
+		logger.LogMessage(MessageIdFormat, 19, "Validating as a JSONL resource.")
+		validateLines(source, reader)
+	case "JSON":
+		logger.LogMessage(MessageIdFormat, 47, "Validating as a JSON array resource.")
+		validateArray(source, reader)
+	case "GZ":
+		logger.LogMessage(MessageIdFormat, 20, "Validating a GZ resource.")
+		gzipReader, err := newGzipReader(reader)
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9019, "Fatal error reading inputURL.", err)
+			return false
+		}
+		defer gzipReader.Close()
+		validateCompressed(source, "GZ", gzipReader)
+	case "LZ4", "BZ2", "ZST":
+		logger.LogMessage(MessageIdFormat, 24, "Validating a "+streamType+" resource.")
+		decompressor, err := decompressors[streamType](reader)
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9039, "Fatal error reading inputURL.", err)
+			return false
+		}
+		validateCompressed(source, streamType, decompressor)
+	case "ZIP":
+		logger.LogMessage(MessageIdFormat, 36, "Validating a ZIP resource.")
+		return readZipStream(source, reader)
+	case "TAR":
+		logger.LogMessage(MessageIdFormat, 54, "Validating a TAR resource.")
+		validateTarArchive(source, reader)
+	case "PARQUET":
+		logger.LogMessage(MessageIdFormat, 59, "Validating a PARQUET resource.")
+		return readParquetStream(source, reader)
+	default:
+		logger.LogMessage(MessageIdFormat, 2004, "If this is a valid JSONL file, please rename with the .jsonl extension or use the file type override (--fileType).")
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The file type implied by the response's Content-Disposition file name.
+func contentDispositionType(response *http.Response) string {
+	_, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return fileTypeOf(params["filename"])
+}
+
+// ----------------------------------------------------------------------------
+
+// The file type implied by the response's Content-Encoding, else its
+// Content-Type, empty when neither is recognized.
+func contentHeaderType(response *http.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding")))
+	if fileType, found := contentEncodings[encoding]; found {
+		return fileType
+	}
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return contentTypes[mediaType]
+}
+
+// ----------------------------------------------------------------------------
+
+// Guess the file type from the leading bytes without consuming them.
+func sniffFileType(reader *bufio.Reader) string {
+	head, _ := reader.Peek(512)
+	if bytes.HasPrefix(head, []byte{0x1f, 0x8b}) {
+		return "GZ"
+	}
+	if bytes.HasPrefix(head, []byte{0x04, 0x22, 0x4d, 0x18}) {
+		return "LZ4"
+	}
+	if bytes.HasPrefix(head, []byte("BZh")) {
+		return "BZ2"
+	}
+	if bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		return "ZST"
+	}
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return "ZIP"
+	}
+	if bytes.HasPrefix(head, []byte("PAR1")) {
+		return "PARQUET"
+	}
+	if len(head) >= 262 && bytes.Equal(head[257:262], []byte("ustar")) {
+		return "TAR"
+	}
+	if text := bytes.TrimLeft(head, " \t\r\n\uFEFF"); len(text) > 0 && text[0] == '{' {
+		return "JSONL"
+	} else if len(text) > 0 && text[0] == '[' {
+		return "JSON"
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+
+// Guess the file type of a local file from its leading bytes, empty when
+// unknown or the file can't be read.
+func sniffFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	return sniffFileType(bufio.NewReader(file))
+}
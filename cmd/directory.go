@@ -0,0 +1,99 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Validate every file with a recognized suffix in a directory, descending
+// into subdirectories with --recursive.  Each file gets its own summary and
+// a grand total follows.  Other files are skipped.
+func readDirectory(dir string) bool {
+	recursive := viper.GetBool(Recursive)
+	files, failed := 0, 0
+	linesBefore, badBefore := totalLines, badLines
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(fileTypeOf(entry.Name())) == 0 {
+			logger.LogMessage(MessageIdFormat, 30, "Skipping a file without a recognized suffix: "+path)
+			return nil
+		}
+		output.Println("file:", path)
+		files++
+		if !readFile(path, "") {
+			failed++
+		}
+		if stopRun() {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9036, "Fatal error reading the input directory.", err)
+		output.Println("Unable to read the directory:", err)
+		return false
+	}
+	printGrandTotal(files, failed, linesBefore, badBefore)
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate every file matching a glob pattern, like /data/export-*.jsonl,
+// followed by a grand total.
+func readGlob(pattern string, fileType string) bool {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9037, "Fatal error in the inputURL glob pattern.", err)
+		output.Println("Invalid glob pattern", pattern+":", err)
+		return false
+	}
+	if len(paths) == 0 {
+		logger.LogMessage(MessageIdFormat, 1001, "No files match the inputURL glob pattern: "+pattern)
+		output.Println("Warning: no files match", pattern)
+		return false
+	}
+	files, failed := len(paths), 0
+	linesBefore, badBefore := totalLines, badLines
+	for i, path := range paths {
+		output.Println("file:", path)
+		if !readFile(path, fileType) {
+			failed++
+		}
+		if stopRun() {
+			files = i + 1
+			break
+		}
+	}
+	printGrandTotal(files, failed, linesBefore, badBefore)
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Print the totals of the files validated since the line counts were
+// linesBefore and badBefore.
+func printGrandTotal(files int, failed int, linesBefore int, badBefore int) {
+	output.Println(passFail(badLines-badBefore, fmt.Sprintf("Validated %d file(s), %d lines in total, %d were bad.", files, totalLines-linesBefore, badLines-badBefore)))
+	if failed > 0 {
+		output.Printf("%d file(s) could not be read.\n", failed)
+		raiseStatus(statusInputError)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/senzing/senzing-tools/option"
+	"github.com/spf13/viper"
+)
+
+// How each file type is decompressed, for the --dry-run plan.
+var fileTypeDescriptions = map[string]string{
+	"JSONL":   "uncompressed",
+	"JSON":    "uncompressed",
+	"GZ":      "gzipped",
+	"ZIP":     "zipped",
+	"LZ4":     "LZ4 compressed",
+	"BZ2":     "bzip2 compressed",
+	"ZST":     "zstd compressed",
+	"TAR":     "tar archived",
+	"PARQUET": "Parquet",
+}
+
+// ----------------------------------------------------------------------------
+
+// Resolve the input the way read() does and print the plan, without
+// fetching anything.  Only a local file is opened, to look at its leading
+// bytes.  Returns false when read() would fail to pick a reader.
+func dryRun(inputURL string) bool {
+	fileType := strings.ToUpper(viper.GetString(option.InputFileType))
+	if len(inputURL) == 0 || inputURL == "-" {
+		output.Println("Would validate stdin as", planFileType(fileType, "its leading bytes", ""))
+		return true
+	}
+	if len(inputURL) < 5 {
+		logger.LogMessage(MessageIdFormat, 2002, fmt.Sprintf("Check the inputURL parameter: %s", inputURL))
+		return false
+	}
+	u, err := url.Parse(inputURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9001, "Fatal error parsing inputURL.", err)
+		return false
+	}
+	if viper.GetBool(Follow) {
+		if !followable(u) {
+			return false
+		}
+		output.Println("Would follow file", filePath(u), "for appended uncompressed JSONL")
+		return true
+	}
+	switch u.Scheme {
+	case "file":
+		return dryRunFile(filePath(u), fileType)
+	case "http", "https":
+		name := resourceName(u)
+		if viper.GetBool(WatchRemote) {
+			if len(fileType) == 0 {
+				fileType = fileTypeOf(name)
+			}
+			if fileType != "JSONL" {
+				output.Println("Would not watch", redactURL(inputURL)+", --"+WatchRemote+" only supports uncompressed JSONL resources")
+				return false
+			}
+			output.Println("Would watch", u.Scheme, "resource", redactURL(inputURL), "for appended", describeFileType(fileType))
+			return true
+		}
+		output.Println("Would validate", u.Scheme, "resource", redactURL(inputURL), "as", planFileType(fileType, "the response", fileTypeOf(name)))
+	case "s3", "gs", "az", "sftp", "ftp":
+		name := strings.TrimPrefix(u.Path, "/")
+		output.Println("Would validate", u.Scheme, "object", redactURL(inputURL), "as", planFileType(fileType, "its leading bytes", fileTypeOf(name)))
+	case "kafka":
+		output.Println("Would validate the messages of Kafka topic", strings.TrimPrefix(u.Path, "/"), "on", u.Host)
+	default:
+		logger.LogMessage(MessageIdFormat, 9002, fmt.Sprintf("We don't handle %s input URLs.", u.Scheme))
+		output.Println("Would not validate", redactURL(inputURL)+", the", u.Scheme, "scheme isn't handled")
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The plan for a local file, directory or glob pattern.
+func dryRunFile(path string, fileType string) bool {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		how := "the files"
+		if viper.GetBool(Recursive) {
+			how = "the files, recursively,"
+		}
+		output.Println("Would validate", how, "with a recognized suffix in directory", path)
+		return true
+	}
+	if err != nil && strings.ContainsAny(path, "*?[") {
+		output.Println("Would validate the files matching", path, "as", planFileType(fileType, "the leading bytes or suffix of each", ""))
+		return true
+	}
+	if len(fileType) == 0 {
+		fileType = sniffFile(path)
+	}
+	if len(fileType) == 0 {
+		fileType = fileTypeOf(path)
+	}
+	if len(fileTypeDescriptions[fileType]) == 0 {
+		logger.LogMessage(MessageIdFormat, 2003, "If this is a valid JSONL file, please rename with the .jsonl extension or use the file type override (--fileType).")
+		output.Println("Would not validate", path+", its file type is unknown")
+		return false
+	}
+	output.Println("Would validate file", path, "as", describeFileType(fileType))
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Describe reading a file type, like "gzipped JSONL".
+func describeFileType(fileType string) string {
+	records := "JSONL"
+	if fileType == "PARQUET" {
+		records = "rows"
+	} else if viper.GetString(InputFormat) == inputFormatJSONArray || (!viper.IsSet(InputFormat) && fileType == "JSON") {
+		records = "JSON array"
+	}
+	if description, found := fileTypeDescriptions[fileType]; found {
+		return description + " " + records
+	}
+	return records
+}
+
+// ----------------------------------------------------------------------------
+
+// Describe reading the --input-file-type, or when there is no override, a
+// type detected from a later look at the stream.  The type the name
+// suggests is noted as the fallback.
+func planFileType(fileType string, detectedFrom string, suggested string) string {
+	if len(fileType) > 0 {
+		return describeFileType(fileType)
+	}
+	plan := describeFileType("") + ", its compression detected from " + detectedFrom
+	if len(suggested) > 0 {
+		plan += ", else by its name as " + describeFileType(suggested)
+	}
+	return plan
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// The --encoding of the input, nil when it is already UTF-8.
+var inputEncoding encoding.Encoding
+
+// ----------------------------------------------------------------------------
+
+// Look up the --encoding, by any of the names a web browser accepts for it,
+// like latin1, iso-8859-1 or windows-1252.
+func openEncoding() bool {
+	inputEncoding = nil
+	name := viper.GetString(Encoding)
+	if len(name) == 0 {
+		return true
+	}
+	found, err := htmlindex.Get(name)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9056, fmt.Sprintf("Fatal error, unknown --%s %s.", Encoding, name), err)
+		output.Println("Unknown --"+Encoding, name+":", err)
+		return false
+	}
+	if found != unicode.UTF8 {
+		inputEncoding = found
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Transcode a stream from the --encoding to UTF-8.
+func decodeInput(reader io.Reader) io.Reader {
+	if inputEncoding == nil {
+		return reader
+	}
+	return inputEncoding.NewDecoder().Reader(reader)
+}
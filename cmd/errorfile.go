@@ -0,0 +1,67 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// errorFileSink writes every invalid line to a JSON-lines file.
+type errorFileSink struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// errorEntry is one line of the --error-file.
+type errorEntry struct {
+	Source   string `json:"source"`
+	Line     int    `json:"line"`
+	Offset   *int64 `json:"offset,omitempty"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	Record   string `json:"record"`
+}
+
+// ----------------------------------------------------------------------------
+
+func newErrorFileSink(path string) (lineSink, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	return &errorFileSink{file: file, writer: writer, encoder: json.NewEncoder(writer)}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *errorFileSink) add(line *lineResult) error {
+	if len(line.category) == 0 {
+		return nil
+	}
+	entry := errorEntry{
+		Source:   redactURL(line.source),
+		Line:     line.number,
+		Category: line.category,
+		Message:  line.message,
+		Record:   line.text(),
+	}
+	if line.offset >= 0 {
+		entry.Offset = &line.offset
+	}
+	return s.encoder.Encode(entry)
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *errorFileSink) close() error {
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// The attribute names from --features-config, upper cased.  nil when the
+// check is off.
+var knownFeatures map[string]bool
+
+// Fields that are part of every record rather than features.
+var identityFields = []string{"DATA_SOURCE", "RECORD_ID"}
+
+// ----------------------------------------------------------------------------
+
+// Load the allowed attribute names from --features-config, one name per
+// line.  Blank lines and lines starting with # are skipped.
+func loadFeatures() bool {
+	featuresConfig := viper.GetString(FeaturesConfig)
+	if len(featuresConfig) == 0 || knownFeatures != nil {
+		return true
+	}
+	file, err := os.Open(featuresConfig)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9028, "Fatal error opening the features config.", err)
+		output.Println("Unable to open the features config:", err)
+		return false
+	}
+	defer file.Close()
+	features := map[string]bool{}
+	for _, name := range identityFields {
+		features[name] = true
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if len(name) > 0 && !strings.HasPrefix(name, "#") {
+			features[strings.ToUpper(name)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9028, "Fatal error reading the features config.", err)
+		output.Println("Unable to read the features config:", err)
+		return false
+	}
+	knownFeatures = features
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The attribute names of a record that aren't in the features config, in
+// sorted order.  Besides the top-level fields, the fields of objects in a
+// list, like "NAMES": [{"NAME_FULL": ...}], are checked.
+func unknownFeatures(fields map[string]interface{}) []string {
+	unknown := map[string]bool{}
+	for name, value := range fields {
+		if !knownFeatures[strings.ToUpper(name)] {
+			unknown[name] = true
+		}
+		if list, ok := value.([]interface{}); ok {
+			for _, element := range list {
+				if object, ok := element.(map[string]interface{}); ok {
+					for nested := range object {
+						if !knownFeatures[strings.ToUpper(nested)] {
+							unknown[name+"."+nested] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// How often --follow looks for appended lines.
+const followInterval = time.Second
+
+// ----------------------------------------------------------------------------
+
+// Whether --follow can follow the input, a single local file.  Following
+// http(s) resources is left to --watch-remote.
+func followable(u *url.URL) bool {
+	if u.Scheme != "file" {
+		logger.LogMessage(MessageIdFormat, 2021, fmt.Sprintf("The --%s option only follows local files, not %s input URLs.", Follow, u.Scheme))
+		output.Printf("--%s only follows a local JSONL file, --%s re-fetches an http(s) resource.\n", Follow, WatchRemote)
+		return false
+	}
+	path := filePath(u)
+	info, err := os.Stat(path)
+	if (err == nil && info.IsDir()) || (err != nil && strings.ContainsAny(path, "*?[")) {
+		logger.LogMessage(MessageIdFormat, 2021, fmt.Sprintf("The --%s option only follows a single file, not %s.", Follow, path))
+		output.Printf("--%s only follows a single JSONL file, not a directory or pattern.\n", Follow)
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Follow an append-only JSONL file like tail -f.  Once the end is reached the
+// file is checked every second and the lines appended since are validated,
+// a trailing partial line waits for its newline.  Counts are kept across
+// reads and the final summary is reported when interrupted, when the
+// --timeout passes, or when nothing was appended for the --follow-timeout.
+// A file that shrinks was truncated and is followed again from its start.
+func followFile(path string, fileType string) bool {
+	fileType = strings.ToUpper(fileType)
+	if len(fileType) == 0 {
+		fileType = sniffFile(path)
+	}
+	if len(fileType) == 0 {
+		fileType = fileTypeOf(path)
+	}
+	if fileType != "JSONL" {
+		logger.LogMessage(MessageIdFormat, 2021, fmt.Sprintf("The --%s option only supports uncompressed JSONL files.", Follow))
+		output.Printf("--%s only follows uncompressed JSONL files, %s is %s.\n", Follow, path, describeFileType(fileType))
+		return false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9067, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer file.Close()
+	idleTimeout := time.Duration(viper.GetInt(FollowTimeout)) * time.Second
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	result := newSummary(path)
+	var offset int64
+	lastAppended := time.Now()
+	for {
+		if info, err := file.Stat(); err == nil && info.Size() < offset {
+			logger.LogMessage(MessageIdFormat, 1003, fmt.Sprintf("%s was truncated, following it from its start.", path))
+			output.Println(path, "was truncated, following it from its start")
+			offset = 0
+		}
+		consumed, err := readAppended(file, offset, result)
+		if err != nil && timedOut.Load() {
+			result.StoppedEarly, result.TimedOut = true, true
+		} else if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2005, "Error reading appended lines from inputURL.", err)
+		}
+		if consumed > 0 {
+			offset += consumed
+			lastAppended = time.Now()
+			output.Printf("Validated %d lines, %d were bad.\n", result.TotalLines, result.bad())
+			output.Flush()
+		}
+		if result.StoppedEarly {
+			result.report()
+			return true
+		}
+		if idleTimeout > 0 && time.Since(lastAppended) >= idleTimeout {
+			logger.LogMessage(MessageIdFormat, 62, fmt.Sprintf("Stopped following %s, nothing was appended for the --%s.", path, FollowTimeout))
+			output.Printf("Stopped following, nothing was appended for %s, the --%s.\n", idleTimeout, FollowTimeout)
+			result.report()
+			return true
+		}
+		select {
+		case <-signals:
+			result.report()
+			return true
+		case <-runContext.Done():
+			result.StoppedEarly, result.TimedOut = true, true
+			result.report()
+			return true
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the complete lines of a file from offset onward.  Returns the
+// number of bytes consumed.
+func readAppended(file *os.File, offset int64, result *summary) (int64, error) {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return validateAppended(file, offset, result)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// A line longer than --max-line-bytes appended to a followed file is counted
+// once it is complete and read past, the lines after it are validated.
+func TestFollowLineTooLong(t *testing.T) {
+	useOptions(t, map[string]interface{}{MaxLineBytes: 64})
+	captureOutput(t)
+	path := t.TempDir() + "/records.jsonl"
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	long := `{"DATA_SOURCE":"TEST","RECORD_ID":"2","NAME_FULL":"` + strings.Repeat("x", 100) + `"}`
+	appends := []struct {
+		text     string
+		consumed int64
+		lines    int
+	}{
+		{`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}` + "\n", 39, 1},
+		// the long line isn't complete, it waits for its newline
+		{long[:80], 0, 1},
+		{long[80:], 0, 1},
+		{"\n" + `{"DATA_SOURCE":"TEST","RECORD_ID":"3"}` + "\n", int64(len(long)) + 1 + 39, 3},
+		{`{"DATA_SOURCE":"TEST",`, 0, 3},
+	}
+	result := newSummary(path)
+	var offset int64
+	for i, appended := range appends {
+		if _, err := file.WriteString(appended.text); err != nil {
+			t.Fatal(err)
+		}
+		consumed, err := readAppended(file, offset, result)
+		if err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+		if consumed != appended.consumed || result.TotalLines != appended.lines {
+			t.Errorf("append %d consumed %d bytes, %d lines, want %d bytes, %d lines", i, consumed, result.TotalLines, appended.consumed, appended.lines)
+		}
+		offset += consumed
+	}
+	if result.LineTooLong != 1 || result.bad() != 1 {
+		t.Errorf("%d lines too long and %d bad, want 1 of each", result.LineTooLong, result.bad())
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/senzing/senzing-tools/option"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Stream a gs://bucket/object into the validator.  Authentication uses
+// Application Default Credentials, so it works on GKE and locally after
+// "gcloud auth application-default login".  The object type follows
+// --input-file-type or the object name's suffix, so gzipped objects are
+// decompressed.  With --state-file an object whose ETag already validated
+// cleanly is skipped.
+func readGCSObject(u *url.URL) bool {
+	bucket := u.Host
+	name := strings.TrimPrefix(u.Path, "/")
+	if len(bucket) == 0 || len(name) == 0 {
+		logger.LogMessage(MessageIdFormat, 9033, fmt.Sprintf("Fatal error, a GCS inputURL needs a bucket and an object: %s", u.String()))
+		output.Println("A GCS inputURL looks like gs://bucket/path/file.jsonl")
+		return false
+	}
+	client, err := storage.NewClient(runContext)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9034, "Fatal error creating the GCS client.", err)
+		output.Println("Unable to create the GCS client:", err)
+		return false
+	}
+	defer client.Close()
+	handle := client.Bucket(bucket).Object(name)
+	object, err := handle.NewReader(runContext)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9035, "Fatal error retrieving the GCS object.", err)
+		output.Println("Unable to get", u.String()+":", err)
+		return false
+	}
+	defer object.Close()
+	// the reader's attributes have no ETag, so it takes a metadata request,
+	// made only with --state-file, of the generation being read
+	etag := func() string {
+		attrs, err := handle.Attrs(runContext)
+		if err != nil || attrs.Generation != object.Attrs.Generation {
+			return ""
+		}
+		return attrs.Etag
+	}
+	return readUnlessStored(u, etag, func() bool { return readGCSBody(u, name, object) })
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the content of a GCS object.
+func readGCSBody(u *url.URL, name string, object *storage.Reader) bool {
+	// a transcoded object has no meaningful size, Remain is -1 then
+	size := object.Remain()
+	body := &countingReader{reader: object}
+	reader := bufio.NewReader(trackProgress(body, size))
+	source := u.String()
+	if !validateTypedStream(source, streamType(name, viper.GetString(option.InputFileType), reader), reader) {
+		return false
+	}
+	return checkSize(size, body)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Load the default options and then the given ones, as Run would, with only
+// errors logged.  The options are reset when the test ends.
+func useOptions(t *testing.T, options map[string]interface{}) {
+	t.Helper()
+	viper.Reset()
+	loadOptions(RootCmd)
+	for key, value := range options {
+		viper.Set(key, value)
+	}
+	logger.SetLevel(logger.LevelError)
+	t.Cleanup(viper.Reset)
+}
+
+// ----------------------------------------------------------------------------
+
+// Send the output to a buffer for the rest of the test.  Flush the output
+// before reading the buffer.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	captured := &bytes.Buffer{}
+	saved := output
+	output = newSyncWriter(captured)
+	t.Cleanup(func() { output = saved })
+	return captured
+}
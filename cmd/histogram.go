@@ -0,0 +1,94 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The widest bar of the --histogram-buckets histogram.
+const histogramWidth = 40
+
+// histogramBucket counts the bad lines of one --histogram-buckets range of
+// lines, by category.
+type histogramBucket struct {
+	FirstLine  int            `json:"firstLine"`
+	LastLine   int            `json:"lastLine"`
+	Bad        int            `json:"bad"`
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a bad line in the bucket of its line number.
+func (s *summary) countInHistogram(category string, lineNumber int) {
+	if s.histogramBuckets <= 0 || lineNumber < 1 {
+		return
+	}
+	s.growHistogram(lineNumber)
+	bucket := &s.Histogram[(lineNumber-1)/s.histogramBuckets]
+	bucket.Bad++
+	if bucket.Categories == nil {
+		bucket.Categories = map[string]int{}
+	}
+	bucket.Categories[category]++
+}
+
+// ----------------------------------------------------------------------------
+
+// Add the buckets up to the one of the line number, so the ranges without
+// bad lines show up empty.
+func (s *summary) growHistogram(lineNumber int) {
+	for len(s.Histogram)*s.histogramBuckets < lineNumber {
+		first := len(s.Histogram)*s.histogramBuckets + 1
+		s.Histogram = append(s.Histogram, histogramBucket{FirstLine: first, LastLine: first + s.histogramBuckets - 1})
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Print a bar for each bucket, scaled to the fullest one, with its counts by
+// category.  The buckets run to the last line read, where the last one ends.
+func (s *summary) printHistogram() {
+	if s.histogramBuckets <= 0 || s.bad() == 0 {
+		return
+	}
+	s.growHistogram(s.lines)
+	last := &s.Histogram[len(s.Histogram)-1]
+	last.LastLine = max(min(last.LastLine, s.lines), last.FirstLine)
+	fullest := 0
+	for _, bucket := range s.Histogram {
+		fullest = max(fullest, bucket.Bad)
+	}
+	output.Printf("  Bad lines by line range, %d line(s) to a bucket:\n", s.histogramBuckets)
+	for _, bucket := range s.Histogram {
+		bar := strings.Repeat("#", (bucket.Bad*histogramWidth+fullest-1)/fullest)
+		row := fmt.Sprintf("    %12s  %8d  %-*s  %s", fmt.Sprintf("%d-%d", bucket.FirstLine, bucket.LastLine), bucket.Bad, histogramWidth, bar, bucket.categories())
+		output.Println(strings.TrimRight(row, " "))
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The categories of a bucket, like "malformed 3, noRecordId 1", the largest
+// count first.
+func (b histogramBucket) categories() string {
+	categories := make([]string, 0, len(b.Categories))
+	for category := range b.Categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if b.Categories[categories[i]] != b.Categories[categories[j]] {
+			return b.Categories[categories[i]] > b.Categories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	counts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		counts = append(counts, fmt.Sprintf("%s %d", category, b.Categories[category]))
+	}
+	return strings.Join(counts, ", ")
+}
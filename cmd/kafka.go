@@ -0,0 +1,184 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/segmentio/kafka-go"
+	"github.com/spf13/viper"
+)
+
+// How often the rolling summary is printed while consuming a topic.
+const kafkaSummaryInterval = 10 * time.Second
+
+// ----------------------------------------------------------------------------
+
+// Consume a kafka://broker[,broker...]/topic input, validating each message
+// value as a record.  With a --kafka-group, the group is assigned the
+// partitions and the offsets of validated messages are committed to it,
+// otherwise every partition of the topic is read from the
+// --kafka-start-offset.  Consumption stops when no message arrives for
+// --kafka-idle-timeout seconds, unless that is 0, or on an interrupt, then
+// the summary is reported.
+func readKafkaTopic(u *url.URL) bool {
+	topic := strings.Trim(u.Path, "/")
+	if len(u.Host) == 0 || len(topic) == 0 {
+		logger.LogMessage(MessageIdFormat, 9025, fmt.Sprintf("Fatal error, a Kafka inputURL needs a broker and a topic: %s", u.String()))
+		output.Println("A Kafka inputURL looks like kafka://broker:9092/topic")
+		return false
+	}
+	config := kafka.ReaderConfig{
+		Brokers: strings.Split(u.Host, ","),
+		Topic:   topic,
+		GroupID: viper.GetString(KafkaGroup),
+	}
+	switch strings.ToLower(viper.GetString(KafkaStartOffset)) {
+	case "first":
+		config.StartOffset = kafka.FirstOffset
+	case "last":
+		config.StartOffset = kafka.LastOffset
+	default:
+		logger.LogMessage(MessageIdFormat, 9026, fmt.Sprintf("Fatal error, --%s must be first or last.", KafkaStartOffset))
+		output.Println("--"+KafkaStartOffset, "must be first or last.")
+		return false
+	}
+
+	ctx, stop := signal.NotifyContext(runContext, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	readers, err := kafkaReaders(ctx, config)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2012, "Error reading from the Kafka topic.", err)
+		output.Println("Error reading from the Kafka topic:", err)
+		return false
+	}
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+	// the readers stop fetching once consumption stops
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages := make(chan kafkaMessage)
+	failures := make(chan error, len(readers))
+	for _, reader := range readers {
+		go fetchKafkaMessages(fetchCtx, reader, messages, failures)
+	}
+
+	var idle <-chan time.Time
+	idleTimeout := time.Duration(viper.GetInt(KafkaIdleTimeout)) * time.Second
+	idleTimer := time.NewTimer(idleTimeout)
+	defer idleTimer.Stop()
+	if idleTimeout > 0 {
+		idle = idleTimer.C
+	}
+
+	result := newSummary(u.String())
+	checks := newLineChecks(result)
+	lastSummary := time.Now()
+consume:
+	for {
+		select {
+		case message := <-messages:
+			if !validateLine(checks, result, message.Value) {
+				break consume
+			}
+			if len(config.GroupID) > 0 {
+				if err := message.reader.CommitMessages(ctx, message.Message); err != nil && ctx.Err() == nil {
+					logger.LogMessageFromError(MessageIdFormat, 2012, "Error committing the offset of a Kafka message.", err)
+				}
+			}
+			if time.Since(lastSummary) >= kafkaSummaryInterval {
+				output.Printf("Validated %d messages so far, %d were bad.\n", result.TotalLines, result.bad())
+				output.Flush()
+				lastSummary = time.Now()
+			}
+			if idle != nil {
+				idleTimer.Reset(idleTimeout)
+			}
+		case err := <-failures:
+			if ctx.Err() == nil {
+				logger.LogMessageFromError(MessageIdFormat, 2012, "Error reading from the Kafka topic.", err)
+				output.Println("Error reading from the Kafka topic:", err)
+				result.report()
+				return false
+			}
+		case <-idle:
+			break consume
+		case <-ctx.Done():
+			if timedOut.Load() {
+				result.StoppedEarly, result.TimedOut = true, true
+			}
+			break consume
+		}
+	}
+	result.report()
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// kafkaMessage is a message fetched from a topic, with the reader to commit
+// it to.
+type kafkaMessage struct {
+	kafka.Message
+	reader *kafka.Reader
+}
+
+// ----------------------------------------------------------------------------
+
+// The readers of a topic: that of the consumer group when there is one,
+// otherwise one for each partition, starting at the StartOffset.
+func kafkaReaders(ctx context.Context, config kafka.ReaderConfig) ([]*kafka.Reader, error) {
+	if len(config.GroupID) > 0 {
+		return []*kafka.Reader{kafka.NewReader(config)}, nil
+	}
+	var partitions []kafka.Partition
+	var err error
+	for _, broker := range config.Brokers {
+		if partitions, err = kafka.DefaultDialer.LookupPartitions(ctx, "tcp", broker, config.Topic); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	readers := make([]*kafka.Reader, 0, len(partitions))
+	for _, partition := range partitions {
+		partitionConfig := config
+		partitionConfig.Partition = partition.ID
+		reader := kafka.NewReader(partitionConfig)
+		reader.SetOffset(config.StartOffset)
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Fetch the messages of a reader until ctx is done or the fetch fails.
+// Messages are committed by the consumer once validated.
+func fetchKafkaMessages(ctx context.Context, reader *kafka.Reader, messages chan<- kafkaMessage, failures chan<- error) {
+	for {
+		message, err := reader.FetchMessage(ctx)
+		if err != nil {
+			failures <- err
+			return
+		}
+		select {
+		case messages <- kafkaMessage{Message: message, reader: reader}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
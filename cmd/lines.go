@@ -0,0 +1,19 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/roncewind/validate/jsonl"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// A scanner of the lines of reader, with lines up to --max-line-bytes.
+func newLineScanner(reader io.Reader) (*bufio.Scanner, *jsonl.Splitter) {
+	return jsonl.NewScanner(reader, viper.GetInt(MaxLineBytes))
+}
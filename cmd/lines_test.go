@@ -0,0 +1,61 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// A byte order mark before the first line is skipped, so the line validates,
+// and the byte offsets still count it as the file's bytes.
+func TestByteOrderMarkFirstLine(t *testing.T) {
+	useOptions(t, nil)
+	captured := captureOutput(t)
+	file, err := os.Open("testdata/bom.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	result := newSummary("testdata/bom.jsonl")
+	scanner, splitter := newLineScanner(file)
+	validateScanner(scanner, splitter, result)
+	output.Flush()
+
+	if result.TotalLines != 3 || result.Malformed != 0 || result.bad() != 1 || result.EmptyDataSource != 1 {
+		t.Fatalf("got %d lines, %d malformed and %d bad, want 3 lines and only line 2 bad:\n%s", result.TotalLines, result.Malformed, result.bad(), captured.String())
+	}
+	// 3 bytes of mark, then the 38 bytes and newline of line 1
+	if want := "Line 2 at byte 42 has an empty DATA_SOURCE field"; !strings.Contains(captured.String(), want) {
+		t.Fatalf("want %q in:\n%s", want, captured.String())
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The offsets the splitter reports for each line of the fixture.
+func TestByteOrderMarkOffsets(t *testing.T) {
+	useOptions(t, nil)
+	content, err := os.ReadFile("testdata/bom.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, splitter := newLineScanner(strings.NewReader(string(content)))
+	want := []int64{3, 42, 77}
+	for i, offset := range want {
+		if !scanner.Scan() {
+			t.Fatalf("only %d lines scanned", i)
+		}
+		if splitter.Offset != offset {
+			t.Errorf("line %d at byte %d, want %d", i+1, splitter.Offset, offset)
+		}
+		if i == 0 && !strings.HasPrefix(scanner.Text(), "{") {
+			t.Errorf("line 1 starts with %q, the mark wasn't skipped", scanner.Text()[:3])
+		}
+	}
+}
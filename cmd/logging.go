@@ -0,0 +1,120 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// Values of --log-format.
+const (
+	logFormatJSON = "json"
+	logFormatText = "text"
+)
+
+// jsonLogWriter turns the log lines of the logger, a level name followed by
+// the message JSON, into single JSON objects with a timestamp.
+type jsonLogWriter struct {
+	writer io.Writer
+}
+
+// The --log-file, nil when logging to the console.
+var logFile *os.File
+
+// ----------------------------------------------------------------------------
+
+// Open --log-file and send the per-line errors and summaries to it.  The
+// JSON summaries of --report-format json stay on stdout.
+func openLogFile() bool {
+	path := viper.GetString(LogFile)
+	if len(path) == 0 {
+		return true
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if viper.GetBool(LogFileAppend) {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9052, "Fatal error opening the log file.", err)
+		fmt.Fprintln(os.Stderr, "Unable to open --"+LogFile+":", err)
+		return false
+	}
+	logFile = file
+	output = newSyncWriter(file)
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Flush the output to the --log-file and close it.
+func closeLogFile() {
+	if logFile != nil {
+		output.Flush()
+		logFile.Close()
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Switch the log output to --log-format, on the --log-file when given.
+// Returns false for an unknown format.
+func setLogFormat() bool {
+	var writer io.Writer = os.Stderr
+	if logFile != nil {
+		writer = logFile
+	}
+	switch viper.GetString(LogFormat) {
+	case logFormatJSON:
+		log.SetFlags(0)
+		log.SetOutput(&jsonLogWriter{writer: writer})
+	case logFormatText:
+		log.SetOutput(writer)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown --%s %s, use %s or %s.\n", LogFormat, viper.GetString(LogFormat), logFormatText, logFormatJSON)
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Write each log line as an object with the time, level, id, text and
+// details of the message.  A line that isn't a message, like the empty one
+// log.Fatal writes, is kept as text or dropped when empty.
+func (w *jsonLogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		level, message, _ := strings.Cut(line, " ")
+		entry := map[string]interface{}{}
+		decoder := json.NewDecoder(strings.NewReader(message))
+		decoder.UseNumber()
+		if err := decoder.Decode(&entry); err != nil {
+			entry = map[string]interface{}{"text": message}
+		}
+		if _, found := entry["level"]; !found {
+			entry["level"] = strings.ToLower(level)
+		}
+		entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+		content, err := json.Marshal(entry)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := w.writer.Write(append(content, '\n')); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
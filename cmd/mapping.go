@@ -0,0 +1,56 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// The standard field names by the alternate names given with
+// --record-id-field and --data-source-field, nil when none are given.
+func fieldMapping() map[string]string {
+	mapping := map[string]string{}
+	if name := viper.GetString(RecordIdField); len(name) > 0 && name != "RECORD_ID" {
+		mapping[name] = "RECORD_ID"
+	}
+	if name := viper.GetString(DataSourceField); len(name) > 0 && name != "DATA_SOURCE" {
+		mapping[name] = "DATA_SOURCE"
+	}
+	if len(mapping) == 0 {
+		return nil
+	}
+	return mapping
+}
+
+// ----------------------------------------------------------------------------
+
+// The line with its alternate field names renamed to the standard ones.  A
+// record that already has the standard field keeps it and the alternate
+// field as they are.  Returns false when nothing was renamed, including for
+// a line that isn't a JSON object.
+func mapFields(line []byte, mapping map[string]string) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(line, &fields) != nil || fields == nil {
+		return nil, false
+	}
+	renamed := false
+	for alternate, standard := range mapping {
+		value, found := fields[alternate]
+		if _, taken := fields[standard]; !found || taken {
+			continue
+		}
+		fields[standard] = value
+		delete(fields, alternate)
+		renamed = true
+	}
+	if !renamed {
+		return nil, false
+	}
+	mapped, err := json.Marshal(fields)
+	return mapped, err == nil
+}
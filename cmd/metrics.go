@@ -0,0 +1,92 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+)
+
+// metrics are the Prometheus counters of a run.
+type metrics struct {
+	lines    prometheus.Counter
+	badLines *prometheus.CounterVec
+	bytes    prometheus.Counter
+}
+
+// The counters served on --metrics-port, nil when it isn't given.
+var runMetrics *metrics
+
+// ----------------------------------------------------------------------------
+
+// Serve the Prometheus counters on --metrics-port at /metrics.  The server
+// runs until the process exits.
+func openMetrics() bool {
+	port := viper.GetInt(MetricsPort)
+	if port == 0 || runMetrics != nil {
+		return true
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9051, "Fatal error listening on the metrics port.", err)
+		output.Println("Unable to serve metrics:", err)
+		return false
+	}
+	runMetrics = &metrics{
+		lines: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "validate_lines_total",
+			Help: "Non-blank lines validated.",
+		}),
+		badLines: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "validate_bad_lines_total",
+			Help: "Lines that failed validation, by category.",
+		}, []string{"category"}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "validate_input_bytes_total",
+			Help: "Bytes read from the inputs, before decompression.",
+		}),
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(runMetrics.lines, runMetrics.badLines, runMetrics.bytes)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	go http.Serve(listener, mux)
+	logger.LogMessage(MessageIdFormat, 44, fmt.Sprintf("Serving metrics on %s.", listener.Addr()))
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a validated line.
+func (m *metrics) countLine(line *lineResult) {
+	if line.skipped {
+		return
+	}
+	m.lines.Inc()
+	if len(line.category) > 0 {
+		m.badLines.WithLabelValues(line.category).Inc()
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// meteredReader counts the bytes read through it in the metrics.
+type meteredReader struct {
+	reader io.Reader
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	runMetrics.bytes.Add(float64(n))
+	return n, err
+}
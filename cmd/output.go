@@ -0,0 +1,99 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+
+// syncWriter serializes writes to a buffered writer, each call writes whole
+// lines so output from concurrent goroutines never interleaves.
+type syncWriter struct {
+	lock   sync.Mutex
+	writer *bufio.Writer
+	// the writer held back output goes to, see hold
+	target *bufio.Writer
+	held   bytes.Buffer
+}
+
+// All per-line error and progress output goes through here.
+var output = newSyncWriter(os.Stdout)
+
+// ----------------------------------------------------------------------------
+
+func newSyncWriter(writer io.Writer) *syncWriter {
+	return &syncWriter{writer: bufio.NewWriter(writer)}
+}
+
+// ----------------------------------------------------------------------------
+
+func (w *syncWriter) Println(a ...interface{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	fmt.Fprintln(w.writer, a...)
+}
+
+// ----------------------------------------------------------------------------
+
+func (w *syncWriter) Printf(format string, a ...interface{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	fmt.Fprintf(w.writer, format, a...)
+}
+
+// ----------------------------------------------------------------------------
+
+func (w *syncWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.writer.Flush()
+}
+
+// ----------------------------------------------------------------------------
+
+// Hold back the output, for --summary-only-on-error, until the run turns out
+// to have a problem.
+func (w *syncWriter) hold() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.target == nil {
+		w.target = w.writer
+		w.writer = bufio.NewWriter(&w.held)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Write the output held back so far and stop holding it.
+func (w *syncWriter) release() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.target == nil {
+		return
+	}
+	w.writer.Flush()
+	w.target.Write(w.held.Bytes())
+	w.writer, w.target = w.target, nil
+	w.held.Reset()
+}
+
+// ----------------------------------------------------------------------------
+
+// Drop the output held back so far and stop holding it.
+func (w *syncWriter) discard() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.target == nil {
+		return
+	}
+	w.writer, w.target = w.target, nil
+	w.held.Reset()
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/parquet-go/parquet-go"
+)
+
+// ----------------------------------------------------------------------------
+
+// opens a Parquet file and validates each of its rows as a record
+func readParquetFile(parquetFile string) bool {
+	file, err := os.Open(parquetFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9063, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer file.Close()
+	return validateParquet(parquetFile, file, fileSize(file))
+}
+
+// ----------------------------------------------------------------------------
+
+// retrieves a Parquet file and validates each of its rows as a record
+func readParquetResource(parquetURL string) bool {
+	response, err := getResource(parquetURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9064, "Fatal error retrieving inputURL.", err)
+		return false
+	}
+	defer response.Body.Close()
+	body := &countingReader{reader: response.Body}
+	if !readParquetStream(parquetURL, trackProgress(body, response.ContentLength)) {
+		return false
+	}
+	return checkContentLength(response, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate a Parquet file that arrives as a stream.  A Parquet file has its
+// footer at the end and needs random access, so the stream is spooled to a
+// temporary file first.
+func readParquetStream(source string, reader io.Reader) bool {
+	spool, err := os.CreateTemp("", "validate-*.parquet")
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9065, "Fatal error creating a temporary file for the Parquet file.", err)
+		return false
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9064, "Fatal error retrieving inputURL.", err)
+		output.Println("Unable to download the Parquet file:", err)
+		return false
+	}
+	inputProgress = nil
+	return validateParquet(source, spool, size)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the rows of a Parquet file and report them.
+func validateParquet(source string, file io.ReaderAt, size int64) bool {
+	parquetFile, err := parquet.OpenFile(file, size)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9066, "Fatal error opening the Parquet file.", err)
+		output.Println("Unable to open the Parquet file:", err)
+		return false
+	}
+	result := newSummary(source)
+	validateParquetRows(parquet.NewReader(parquetFile), result)
+	result.report()
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate each row of a Parquet file as a record, numbered by its position
+// in the file.  The columns become the fields of the record, leaving out the
+// null ones.  Rows are read a row group at a time, so memory stays bounded by
+// the largest row group.
+func validateParquetRows(reader *parquet.Reader, result *summary) {
+	checks := newLineChecks(result)
+	checks.elements = "Row"
+	for {
+		row := map[string]interface{}{}
+		err := reader.Read(&row)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			rowsError(result, err)
+			return
+		}
+		for column, value := range row {
+			if value == nil {
+				delete(row, column)
+			}
+		}
+		line, err := json.Marshal(row)
+		if err != nil {
+			rowsError(result, err)
+			return
+		}
+		if !validateLine(checks, result, line) {
+			return
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Report a Parquet file whose rows can't be read any further.  The rows
+// validated so far are kept.
+func rowsError(result *summary, err error) {
+	result.Incomplete = true
+	logger.LogMessageFromError(MessageIdFormat, 2019, "Error reading the rows of the Parquet input.", err)
+	output.Println("Stopped reading", result.Source+", row", result.lines+1, "could not be read:", err)
+	raiseStatus(statusInputError)
+}
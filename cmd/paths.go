@@ -0,0 +1,61 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+
+// The file URLs of the paths given as arguments, so `validate ~/data.jsonl`
+// reads like --input-url file:///home/me/data.jsonl.  A leading ~ is the home
+// directory and relative paths are taken from the working directory.  "-"
+// stays stdin.
+func fileURLs(paths []string) []string {
+	urls := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path == "-" {
+			urls = append(urls, path)
+			continue
+		}
+		urls = append(urls, (&url.URL{Scheme: "file", Path: expandPath(path)}).String())
+	}
+	return urls
+}
+
+// ----------------------------------------------------------------------------
+
+// The absolute form of a path, with a leading ~ expanded to the home
+// directory.  A path that can't be expanded is returned as given.
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return path
+}
+
+// ----------------------------------------------------------------------------
+
+// The local path of a file:// URL.  The ? of a glob pattern, as in
+// file:///data/part-?.jsonl, starts the query of the URL, so the query is put
+// back in the path.
+func filePath(u *url.URL) string {
+	if len(u.RawQuery) == 0 && !u.ForceQuery {
+		return u.Path
+	}
+	query, err := url.PathUnescape(u.RawQuery)
+	if err != nil {
+		query = u.RawQuery
+	}
+	return u.Path + "?" + query
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// The ? of a glob pattern in a file:// URL is part of the path, not a query.
+func TestFilePath(t *testing.T) {
+	tests := map[string]string{
+		"file:///data/records.jsonl":     "/data/records.jsonl",
+		"file:///data/part-?.jsonl":      "/data/part-?.jsonl",
+		"file:///data/part-??.jsonl":     "/data/part-??.jsonl",
+		"file:///data/a?b?c.jsonl":       "/data/a?b?c.jsonl",
+		"file:///data/part?":             "/data/part?",
+		"file:///data/a%20b-?.jsonl":     "/data/a b-?.jsonl",
+		"file:///data/p%3F-?.jsonl":      "/data/p?-?.jsonl",
+		"file:///data/part-?%20x.jsonl":  "/data/part-? x.jsonl",
+		"file:///data/part-[0-9]*.jsonl": "/data/part-[0-9]*.jsonl",
+	}
+	for rawURL, want := range tests {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := filePath(u); path != want {
+			t.Errorf("%s has path %s, want %s", rawURL, path, want)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// A file:// glob with a ? validates each matching file.
+func TestFileURLGlobQuestionMark(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"part-1.jsonl", "part-2.jsonl", "part-10.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}`+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	useOptions(t, nil)
+	buffer := captureOutput(t)
+	status := readInputURL("file://" + filepath.ToSlash(dir) + "/part-?.jsonl")
+	output.Flush()
+	if status != statusClean {
+		t.Errorf("the status is %d, want %d:\n%s", status, statusClean, buffer.String())
+	}
+	for _, name := range []string{"part-1.jsonl", "part-2.jsonl"} {
+		if !strings.Contains(buffer.String(), name) {
+			t.Errorf("%s wasn't validated:\n%s", name, buffer.String())
+		}
+	}
+	if strings.Contains(buffer.String(), "part-10.jsonl") {
+		t.Errorf("part-10.jsonl doesn't match part-?.jsonl:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/pgzip"
+)
+
+// parallelGzipReader decompresses a gzip stream with pgzip one member at a
+// time.  pgzip reports a stream cut short at or before a member's trailer as
+// a clean end, so the checksum and size of each member are checked again
+// against its trailer, and a mismatch is reported as io.ErrUnexpectedEOF,
+// like the standard library reader does.
+type parallelGzipReader struct {
+	gzip        *pgzip.Reader
+	source      *trailerReader
+	multistream bool
+	digest      hash.Hash32
+	size        uint32
+	err         error
+}
+
+// trailerReader keeps the last 8 bytes read, the trailer of a gzip member
+// once pgzip has read one.
+type trailerReader struct {
+	*bufio.Reader
+	last [8]byte
+}
+
+// ----------------------------------------------------------------------------
+
+// A pgzip reader of 1 MiB blocks, decompressing up to blocks of them ahead,
+// that reads on through every member of concatenated gzip streams when
+// multistream is set.  pgzip checksums a block while it decompresses the
+// next, so it needs at least 2 of them.
+func newParallelGzipReader(reader io.Reader, blocks int, multistream bool) (*parallelGzipReader, error) {
+	source := &trailerReader{Reader: bufio.NewReader(reader)}
+	gzipReader, err := pgzip.NewReaderN(source, gzipBlockSize, max(blocks, 2))
+	if err != nil {
+		return nil, err
+	}
+	gzipReader.Multistream(false)
+	return &parallelGzipReader{gzip: gzipReader, source: source, multistream: multistream, digest: crc32.NewIEEE()}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *parallelGzipReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for {
+		n, err := r.gzip.Read(p)
+		r.digest.Write(p[:n])
+		r.size += uint32(n)
+		if err != io.EOF {
+			r.err = err
+			return n, err
+		}
+		// the end of a member, pgzip has just read its trailer
+		if binary.LittleEndian.Uint32(r.source.last[:4]) != r.digest.Sum32() || binary.LittleEndian.Uint32(r.source.last[4:]) != r.size {
+			r.err = io.ErrUnexpectedEOF
+			return n, r.err
+		}
+		if !r.multistream {
+			r.err = io.EOF
+			return n, r.err
+		}
+		r.digest.Reset()
+		r.size = 0
+		if err := r.gzip.Reset(r.source); err != nil {
+			r.err = err
+			return n, r.err
+		}
+		r.gzip.Multistream(false)
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *parallelGzipReader) Close() error {
+	return r.gzip.Close()
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.keep(p[:n])
+	return n, err
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *trailerReader) ReadByte() (byte, error) {
+	b, err := r.Reader.ReadByte()
+	if err == nil {
+		copy(r.last[:], r.last[1:])
+		r.last[len(r.last)-1] = b
+	}
+	return b, err
+}
+
+// ----------------------------------------------------------------------------
+
+// Keep the last 8 bytes of what was read.
+func (r *trailerReader) keep(read []byte) {
+	if len(read) >= len(r.last) {
+		copy(r.last[:], read[len(read)-len(r.last):])
+		return
+	}
+	copy(r.last[:], r.last[len(read):])
+	copy(r.last[len(r.last)-len(read):], read)
+}
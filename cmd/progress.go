@@ -0,0 +1,79 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// progress reports how far validation of the current input has come.
+type progress struct {
+	input    *countingReader
+	size     int64 // -1 when unknown
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+}
+
+// Progress of the input being validated, nil unless --progress is given.
+var inputProgress *progress
+
+// ----------------------------------------------------------------------------
+
+// Measure the raw input against its size in bytes, -1 when unknown, and count
+// its bytes in the metrics.  Returns the reader to continue reading from.
+func trackProgress(reader io.Reader, size int64) io.Reader {
+	if runMetrics != nil {
+		reader = &meteredReader{reader: reader}
+	}
+	if !viper.GetBool(Progress) {
+		return reader
+	}
+	counter := &countingReader{reader: reader}
+	now := time.Now()
+	interval := time.Duration(viper.GetInt(ProgressInterval)) * time.Second
+	inputProgress = &progress{input: counter, size: size, interval: interval, start: now, last: now}
+	return counter
+}
+
+// ----------------------------------------------------------------------------
+
+// Print a status line to stderr once --progress-interval has passed.
+func (p *progress) update(result *summary) {
+	now := time.Now()
+	if now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	elapsed := now.Sub(p.start).Seconds()
+	rate := float64(result.lines) / elapsed
+	status := fmt.Sprintf("Validated %d lines, %d bad, %.0f lines/s", result.TotalLines, result.bad(), rate)
+	if estimate := p.estimateLines(result.lines); estimate > 0 {
+		percent := 100 * float64(p.input.count) / float64(p.size)
+		remaining := time.Duration(float64(estimate-result.lines)/rate) * time.Second
+		status += fmt.Sprintf(", %.0f%% of ~%d lines, ETA ~%s (approximate)", percent, estimate, remaining.Round(time.Second))
+	}
+	fmt.Fprintln(os.Stderr, status)
+}
+
+// ----------------------------------------------------------------------------
+
+// Estimate the total number of lines from the average length of the lines
+// read so far and the input size.  Returns 0 when it can't be estimated.
+func (p *progress) estimateLines(lines int) int {
+	if p.size <= 0 || lines == 0 || p.input.count == 0 {
+		return 0
+	}
+	averageLength := float64(p.input.count) / float64(lines)
+	estimate := int(float64(p.size) / averageLength)
+	if estimate < lines {
+		estimate = lines
+	}
+	return estimate
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/roncewind/validate/jsonl"
+)
+
+// ----------------------------------------------------------------------------
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// ----------------------------------------------------------------------------
+
+// Check that the whole response body was read.  A dropped connection can look
+// like a normal end of stream, so when the server gave a Content-Length it is
+// compared to the bytes actually read.
+func checkContentLength(response *http.Response, body *countingReader) bool {
+	return checkSize(response.ContentLength, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// Check that size bytes, when known, were read from body.  An input
+// abandoned because of --max-errors isn't expected to be read completely.
+func checkSize(size int64, body *countingReader) bool {
+	if size < 0 || body.count == size || stoppedEarly {
+		return true
+	}
+	logger.LogMessage(MessageIdFormat, 9012, fmt.Sprintf("Fatal error, input stream was truncated: read %d of %d bytes.", body.count, size))
+	output.Println("Input stream was truncated, read", body.count, "of", size, "bytes.")
+	return false
+}
+
+// ----------------------------------------------------------------------------
+
+// errorReader remembers the first error, other than io.EOF, returned by the
+// reader it wraps.  bufio.Scanner stops quietly on such errors, so this is how
+// a decoding failure gets reported.
+type errorReader struct {
+	reader io.Reader
+	err    error
+}
+
+// ----------------------------------------------------------------------------
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the lines of a decompressed stream.  When decompression fails
+// partway, e.g. with io.ErrUnexpectedEOF from a truncated gzip file, the lines
+// read so far are still reported and the run ends with statusInputError.
+// It returns true, as the input was read even if not to its end.
+func validateCompressed(source string, format string, reader io.Reader) bool {
+	decoder := &errorReader{reader: reader}
+	err := scanLines(source, decoder)
+	if decoder.err != nil && !timedOut.Load() {
+		err = decoder.err
+	}
+	if err != nil {
+		output.Println("Error decompressing", format, "input", source+", the archive is corrupt or truncated:", err)
+		raiseStatus(statusInputError)
+		// ids of the 9000 range are logged at the info level and don't exit,
+		// the output is flushed so the summary still comes before the log
+		output.Flush()
+		logger.LogMessageFromError(MessageIdFormat, 9022, fmt.Sprintf("Fatal error decompressing %s input, it is corrupt or truncated.", format), err)
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Skip a UTF-8 byte order mark at the start of a stream, as written by some
+// Windows tools, so the first record isn't rejected as malformed.
+func skipBOM(reader io.Reader) io.Reader {
+	buffered := bufio.NewReader(reader)
+	if head, err := buffered.Peek(len(jsonl.UTF8BOM)); err == nil && bytes.Equal(head, jsonl.UTF8BOM) {
+		buffered.Discard(len(jsonl.UTF8BOM))
+	}
+	return buffered
+}
+
+// ----------------------------------------------------------------------------
+
+// Report an input that failed to read partway.  The lines read before the
+// failure have been reported, and the run ends with statusInputError.
+func readFailed(source string, err error) {
+	output.Println("Input stream", source, "ended unexpectedly:", err)
+	raiseStatus(statusInputError)
+	// ids of the 9000 range are logged at the info level and don't exit,
+	// the output is flushed so the summary still comes before the log
+	output.Flush()
+	logger.LogMessageFromError(MessageIdFormat, 9055, "Fatal error reading inputURL, the stream ended unexpectedly.", err)
+}
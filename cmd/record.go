@@ -0,0 +1,58 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/roncewind/validate/jsonl"
+	"github.com/spf13/cobra"
+)
+
+// ----------------------------------------------------------------------------
+
+// recordCmd validates a single record given on the command line.
+var recordCmd = &cobra.Command{
+	Use:   "record JSON",
+	Short: "Validates a single record.",
+	Long: `
+	Validate a single JSON record, passed as an argument, conforms to the Generic Entity Specification.
+	The options, like the spec version, schema and features, are read from the environment and
+	configuration file.
+
+	Usage example:
+
+	validate record '{"DATA_SOURCE":"TEST","RECORD_ID":"1"}'
+	`,
+	Args: cobra.ExactArgs(1),
+	PreRun: func(cobraCommand *cobra.Command, args []string) {
+		loadConfigurationFile(cobraCommand)
+		loadOptions(cobraCommand)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setLogFormat() {
+			os.Exit(exitCodeOf(statusUsageError))
+		}
+		setLogLevel(cmd)
+		if !loadSpec() || !loadSchema() || !loadFeatures() {
+			os.Exit(exitCodeOf(statusUsageError))
+		}
+		checks := &lineChecks{checker: jsonl.NewChecker(recordOptions(nil))}
+		line := checks.prepareBytes(bytes.TrimSpace([]byte(args[0])), "")
+		checks.validate(line)
+		if len(line.category) > 0 {
+			fmt.Println("The record is not valid,", line.category+":", jsonl.MessageText(line.message))
+			os.Exit(exitCodeOf(statusBadLines))
+		}
+		fmt.Println("The record is valid.")
+	},
+}
+
+// ----------------------------------------------------------------------------
+
+func init() {
+	RootCmd.AddCommand(recordCmd)
+}
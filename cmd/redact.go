@@ -0,0 +1,76 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Flag names containing any of these hold values that must not be recorded.
+var sensitiveFlagNames = []string{"header", "password", "secret", "token"}
+
+const redacted = "xxxxx"
+
+// ----------------------------------------------------------------------------
+
+// Copy the command line arguments, hiding credentials.  Values of sensitive
+// flags are replaced and URLs lose their password and query values.
+func redactArguments(args []string) []string {
+	result := make([]string, 0, len(args))
+	redactNext := false
+	for _, arg := range args {
+		switch {
+		case redactNext:
+			arg = redacted
+			redactNext = false
+		case strings.HasPrefix(arg, "-"):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if isSensitiveFlag(name) {
+				if hasValue {
+					arg = arg[:strings.Index(arg, "=")+1] + redacted
+				} else {
+					redactNext = true
+				}
+			} else if hasValue {
+				i := strings.Index(arg, "=") + 1
+				arg = arg[:i] + redactURL(arg[i:])
+			}
+		default:
+			arg = redactURL(arg)
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+// ----------------------------------------------------------------------------
+
+func isSensitiveFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveFlagNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+
+// Hide the password and query values of a URL, other strings are unchanged.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return value
+	}
+	if len(u.RawQuery) > 0 {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, redacted)
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/santhosh-tekuri/jsonschema/v5/httploader"
+	"github.com/spf13/viper"
+)
+
+// The client of every http(s) request, set up by openHTTPClient.
+var httpClient = http.DefaultClient
+
+// ----------------------------------------------------------------------------
+
+// Set up the client that fetches http(s) inputs and schemas.  --http-timeout
+// bounds connecting and waiting for the response headers rather than the
+// whole request, so large inputs may take as long as they need to stream.
+// Requests go through --proxy when given, else the proxy of the environment.
+// Returns false for a --proxy that isn't a URL.
+func openHTTPClient() bool {
+	timeout := time.Duration(viper.GetInt(HttpTimeout)) * time.Second
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if timeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = timeout
+		transport.ResponseHeaderTimeout = timeout
+	}
+	if proxy := viper.GetString(Proxy); len(proxy) > 0 {
+		proxyURL, err := url.Parse(proxy)
+		if err == nil && len(proxyURL.Host) == 0 {
+			err = errors.New("no host")
+		}
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9062, "Fatal error parsing the proxy URL.", err)
+			output.Println("Unable to use --"+Proxy, redactURL(proxy)+":", err)
+			return false
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	httpClient = &http.Client{Transport: transport, CheckRedirect: checkRedirect}
+	httploader.Client = httpClient
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Log each redirect an http(s) request follows, stopping after
+// --max-redirects of them.
+func checkRedirect(request *http.Request, via []*http.Request) error {
+	maxRedirects := viper.GetInt(MaxRedirects)
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects, see --%s", maxRedirects, MaxRedirects)
+	}
+	logger.LogMessage(MessageIdFormat, 57, fmt.Sprintf("Redirected from %s to %s.", redactURL(via[len(via)-1].URL.String()), redactURL(request.URL.String())))
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Build the request for an http(s) input, honoring --http-method, --header
+// and the request body options.
+func newResourceRequest(resourceURL string) (*http.Request, error) {
+	method := strings.ToUpper(viper.GetString(HttpMethod))
+	body, err := requestBody()
+	if err != nil {
+		return nil, err
+	}
+	switch method {
+	case http.MethodGet:
+		if body != nil {
+			return nil, fmt.Errorf("--%s and --%s need --%s POST", HttpBody, HttpBodyFile, HttpMethod)
+		}
+	case http.MethodPost:
+	default:
+		return nil, fmt.Errorf("unsupported --%s %s, use GET or POST", HttpMethod, method)
+	}
+	request, err := http.NewRequestWithContext(runContext, method, resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", viper.GetString(HttpContentType))
+	}
+	return request, addHeaders(request)
+}
+
+// ----------------------------------------------------------------------------
+
+// Add the headers given with --header to a request.
+func addHeaders(request *http.Request) error {
+	for _, header := range viper.GetStringSlice(Header) {
+		key, value, found := strings.Cut(header, ":")
+		if !found || len(strings.TrimSpace(key)) == 0 {
+			return fmt.Errorf("--%s %q is not of the form \"Key: Value\"", Header, header)
+		}
+		request.Header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// The request body from --http-body or --http-body-file, nil when neither is set.
+func requestBody() (io.Reader, error) {
+	body := viper.GetString(HttpBody)
+	bodyFile := viper.GetString(HttpBodyFile)
+	if len(body) > 0 && len(bodyFile) > 0 {
+		return nil, errors.New("use only one of --" + HttpBody + " and --" + HttpBodyFile)
+	}
+	if len(bodyFile) > 0 {
+		content, err := os.ReadFile(bodyFile)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(content), nil
+	}
+	if len(body) > 0 {
+		return strings.NewReader(body), nil
+	}
+	return nil, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Request an http(s) input.  A response without a 2xx status is returned as
+// an error, so an error page isn't validated as a pile of malformed lines.
+func getResource(resourceURL string) (*http.Response, error) {
+	request, err := newResourceRequest(resourceURL)
+	if err != nil {
+		output.Println("Unable to build the request:", err)
+		return nil, err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if finalURL := response.Request.URL.String(); finalURL != request.URL.String() {
+		logger.LogMessage(MessageIdFormat, 58, fmt.Sprintf("Resolved %s to %s.", redactURL(resourceURL), redactURL(finalURL)))
+		output.Println("Redirected to", redactURL(finalURL))
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		output.Println("The server returned", response.Status, "for", redactURL(resourceURL))
+		return nil, fmt.Errorf("unexpected HTTP status: %s", response.Status)
+	}
+	return response, nil
+}
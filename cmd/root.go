@@ -5,28 +5,111 @@ package cmd
 
 import (
 	"bufio"
-	"compress/gzip"
+	"bytes"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/docktermj/go-xyzzy-helpers/logger"
-	"github.com/senzing/go-common/record"
+	"github.com/roncewind/validate/jsonl"
 	"github.com/senzing/senzing-tools/constant"
 	"github.com/senzing/senzing-tools/envar"
 	"github.com/senzing/senzing-tools/option"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
 const (
-	defaultFileType string = ""
-	defaultInputURL string = ""
-	defaultLogLevel string = "error"
+	defaultAzureStorageAccount   string  = ""
+	defaultCheckDuplicates       bool    = false
+	defaultCheckpoint            string  = ""
+	defaultColor                 string  = colorAuto
+	defaultCompareSchema         string  = ""
+	defaultCompareSpecVersion    string  = ""
+	defaultCountOnly             bool    = false
+	defaultDataSourceField       string  = ""
+	defaultDataSourceStats       bool    = false
+	defaultDebugClassification   bool    = false
+	defaultDryRun                bool    = false
+	defaultEncoding              string  = "utf-8"
+	defaultEndLine               int     = 0
+	defaultErrorExitCode         int     = 1
+	defaultErrorFile             string  = ""
+	defaultExamplesPerCategory   int     = 0
+	defaultFailFast              bool    = false
+	defaultFeaturesConfig        string  = ""
+	defaultFileType              string  = ""
+	defaultFollow                bool    = false
+	defaultFollowTimeout         int     = 0
+	defaultGzipMultistream       bool    = true
+	defaultHistogramBuckets      int     = 0
+	defaultHttpBody              string  = ""
+	defaultHttpBodyFile          string  = ""
+	defaultHttpContentType       string  = "application/json"
+	defaultHttpMethod            string  = "GET"
+	defaultHttpTimeout           int     = 30
+	defaultIdentityFile          string  = ""
+	defaultInputFormat           string  = inputFormatJSONL
+	defaultKafkaGroup            string  = ""
+	defaultKafkaIdleTimeout      int     = 30
+	defaultKafkaStartOffset      string  = "first"
+	defaultKnownHosts            string  = ""
+	defaultLogFile               string  = ""
+	defaultLogFileAppend         bool    = false
+	defaultLogFormat             string  = logFormatText
+	defaultLogLevel              string  = "error"
+	defaultMaxErrors             int     = 0
+	defaultMaxLineBytes          int     = jsonl.DefaultMaxLineBytes
+	defaultMaxRedirects          int     = 10
+	defaultMetricsPort           int     = 0
+	defaultOutputInvalid         string  = ""
+	defaultOutputValid           string  = ""
+	defaultParallelGzip          int     = 0
+	defaultPretty                bool    = false
+	defaultProgress              bool    = false
+	defaultProgressInterval      int     = 5
+	defaultProxy                 string  = ""
+	defaultQuiet                 bool    = false
+	defaultRecordIdField         string  = ""
+	defaultRecursive             bool    = false
+	defaultReportDir             string  = ""
+	defaultReportFormat          string  = reportFormatText
+	defaultRequireGrouped        bool    = false
+	defaultRequireUTF8Normalized bool    = false
+	defaultResume                bool    = false
+	defaultRewriteMappedFields   bool    = false
+	defaultSample                int     = 0
+	defaultSampleRate            float64 = 1.0
+	defaultSchema                string  = ""
+	defaultSeed                  int64   = 0
+	defaultSpecVersion           string  = "3"
+	defaultSplitBadFile          string  = ""
+	defaultSplitOutputDir        string  = ""
+	defaultSqliteOut             string  = ""
+	defaultStartLine             int     = 0
+	defaultStateFile             string  = ""
+	defaultStrict                bool    = false
+	defaultSuggestFixes          bool    = false
+	defaultSummaryOnlyOnError    bool    = false
+	defaultTimeout               int     = 0
+	defaultVerbose               bool    = false
+	defaultWatchInterval         int     = 10
+	defaultWatchRemote           bool    = false
+	defaultWorkers               int     = 1
+	defaultZipPassword           string  = ""
+)
+
+var (
+	defaultAllowedDataSource []string = []string{}
+	defaultHeader            []string = []string{}
+	defaultIgnoreFields      []string = []string{}
+	defaultInputURL          []string = []string{}
+	defaultNormalizedFields  []string = []string{}
+	defaultRequireField      []string = []string{}
 )
 
 const (
@@ -34,6 +117,195 @@ const (
 	envVarReplacerCharOld string = "-"
 )
 
+// Options specific to validate that aren't part of senzing-tools/option.
+const (
+	AllowedDataSource        = "allowed-data-source"
+	AzureStorageAccount      = "azure-storage-account"
+	CheckDuplicates          = "check-duplicates"
+	Checkpoint               = "checkpoint"
+	Color                    = "color"
+	CompareSchema            = "compare-schema"
+	CompareSpecVersion       = "compare-spec-version"
+	CountOnly                = "count-only"
+	DataSourceField          = "data-source-field"
+	DataSourceStats          = "data-source-stats"
+	DebugClassification      = "debug-classification"
+	DryRun                   = "dry-run"
+	Encoding                 = "encoding"
+	EndLine                  = "end-line"
+	ErrorExitCode            = "error-exit-code"
+	ErrorFile                = "error-file"
+	ExamplesPerCategory      = "examples-per-category"
+	FailFast                 = "fail-fast"
+	FeaturesConfig           = "features-config"
+	Follow                   = "follow"
+	FollowTimeout            = "follow-timeout"
+	GzipMultistream          = "gzip-multistream"
+	Header                   = "header"
+	HistogramBuckets         = "histogram-buckets"
+	HttpBody                 = "http-body"
+	HttpBodyFile             = "http-body-file"
+	HttpContentType          = "http-content-type"
+	HttpMethod               = "http-method"
+	HttpTimeout              = "http-timeout"
+	IdentityFile             = "identity-file"
+	IgnoreFields             = "ignore-fields"
+	InputFormat              = "input-format"
+	KafkaGroup               = "kafka-group"
+	KafkaIdleTimeout         = "kafka-idle-timeout"
+	KafkaStartOffset         = "kafka-start-offset"
+	KnownHosts               = "known-hosts"
+	LogFile                  = "log-file"
+	LogFileAppend            = "log-file-append"
+	LogFormat                = "log-format"
+	MaxErrors                = "max-errors"
+	MaxLineBytes             = "max-line-bytes"
+	MaxRedirects             = "max-redirects"
+	MetricsPort              = "metrics-port"
+	NormalizedFields         = "normalized-fields"
+	OutputInvalid            = "output-invalid"
+	OutputValid              = "output-valid"
+	ParallelGzip             = "parallel-gzip"
+	Pretty                   = "pretty"
+	Progress                 = "progress"
+	ProgressInterval         = "progress-interval"
+	Proxy                    = "proxy"
+	Quiet                    = "quiet"
+	RecordIdField            = "record-id-field"
+	Recursive                = "recursive"
+	ReportDir                = "report-dir"
+	ReportFormat             = "report-format"
+	RequireField             = "require-field"
+	RequireGroupedDataSource = "require-grouped-data-source"
+	RequireUTF8Normalized    = "require-utf8-normalized"
+	Resume                   = "resume"
+	RewriteMappedFields      = "rewrite-mapped-fields"
+	Sample                   = "sample"
+	SampleRate               = "sample-rate"
+	Schema                   = "schema"
+	Seed                     = "seed"
+	SpecVersion              = "spec-version"
+	SplitBadFile             = "split-bad-file"
+	SplitOutputDir           = "split-output-dir"
+	SqliteOut                = "sqlite-out"
+	StartLine                = "start-line"
+	StateFile                = "state-file"
+	Strict                   = "strict"
+	SuggestFixes             = "suggest-fixes"
+	SummaryOnlyOnError       = "summary-only-on-error"
+	Timeout                  = "timeout"
+	Verbose                  = "verbose"
+	WatchInterval            = "watch-interval"
+	WatchRemote              = "watch-remote"
+	Workers                  = "workers"
+	ZipPassword              = "zip-password"
+)
+
+const (
+	AllowedDataSourceHelp        = "DATA_SOURCE code records may use, may be repeated, any code is allowed when not given"
+	AzureStorageAccountHelp      = "Azure storage account of az:// inputs, default AZURE_STORAGE_ACCOUNT"
+	CheckDuplicatesHelp          = "Flag records reusing a RECORD_ID within their DATA_SOURCE, memory grows with the number of distinct records"
+	CheckpointHelp               = "File to record the progress through an input in, so an interrupted run can --resume"
+	ColorHelp                    = "Color the bad lines and the summary, auto when writing to a terminal, always or never"
+	CompareSchemaHelp            = "A newer JSON Schema, lines that pass one of --schema and --compare-schema but not the other are reported"
+	CompareSpecVersionHelp       = "A second version of the Generic Entity Specification each record is also checked against, records valid under only one of it and --spec-version are counted by direction"
+	CountOnlyHelp                = "Only report the number of lines, valid lines and bad lines, without the errors of each line or their categories"
+	DataSourceFieldHelp          = "Field read as the DATA_SOURCE of records that have no DATA_SOURCE, like source"
+	DataSourceStatsHelp          = "Count the records of each DATA_SOURCE and print the breakdown, largest first"
+	DebugClassificationHelp      = "At startup, print how record.Validate errors for a set of probe records map to categories"
+	DryRunHelp                   = "Print how the input would be read and validated, without reading it"
+	EncodingHelp                 = "Character encoding of the input, like latin1 or windows-1252, transcoded to UTF-8 before validation"
+	EndLineHelp                  = "Last line of each input that is validated, reading stops after it, 0 for the end of the input"
+	ErrorExitCodeHelp            = "Exit code when some lines were bad, for CI systems with their own conventions"
+	ErrorFileHelp                = "JSON-lines file that receives each invalid line with its number and error, instead of the console"
+	ExamplesPerCategoryHelp      = "Number of example line numbers kept for each category of bad lines"
+	FailFastHelp                 = "Stop the whole run at the first bad line, leaving the rest of the input and any remaining inputs unread"
+	FeaturesConfigHelp           = "File listing the allowed feature/attribute names, one per line, records using other names are flagged"
+	FollowHelp                   = "Keep reading a local JSONL file as it is appended to, like tail -f, validating the new lines"
+	FollowTimeoutHelp            = "Seconds without new lines after which --follow stops and reports, 0 follows until interrupted"
+	GzipMultistreamHelp          = "Read every member of a gzip file made of concatenated gzip streams, instead of stopping after the first"
+	HeaderHelp                   = `Header, as "Key: Value", to send with http(s) requests, may be repeated`
+	HistogramBucketsHelp         = "Lines per bucket of a histogram of where the bad lines fall, printed at the end, like 100000, 0 prints none"
+	HttpBodyFileHelp             = "File whose content is sent as the request body with --http-method POST"
+	HttpBodyHelp                 = "Request body sent with --http-method POST"
+	HttpContentTypeHelp          = "Content-Type of the --http-body or --http-body-file request body"
+	HttpMethodHelp               = "HTTP method used to request http(s) input, GET or POST"
+	HttpTimeoutHelp              = "Seconds to wait for an http(s) server to connect and respond, 0 waits forever"
+	IdentityFileHelp             = "Private key file for sftp:// inputs, keys from a running ssh-agent are also tried"
+	IgnoreFieldsHelp             = "Top-level fields removed from each record before the schema, --features-config and --strict checks"
+	InputFormatHelp              = "Format of the decompressed input, jsonl or json-array for a single top-level JSON array of records, when not given an input starting with [ is read as json-array"
+	KafkaGroupHelp               = "Kafka consumer group, offsets are committed to it so a later run resumes where this one stopped, without a group every partition is read"
+	KafkaIdleTimeoutHelp         = "Seconds without a Kafka message after which consumption stops and the summary is reported, 0 consumes until interrupted"
+	KafkaStartOffsetHelp         = "Where to start consuming a Kafka topic without committed offsets, first or last"
+	KnownHostsHelp               = "known_hosts file with the host keys of sftp:// servers, default ~/.ssh/known_hosts"
+	LogFileAppendHelp            = "Append to --log-file rather than truncating it"
+	LogFileHelp                  = "File the log lines, per-line errors and summaries are written to instead of the console"
+	LogFormatHelp                = "Format of the log lines written to stderr, text or json"
+	MaxErrorsHelp                = "Stop validating an input once this many lines are bad, 0 for no limit"
+	MaxLineBytesHelp             = "Longest line, in bytes, that is validated, longer lines are reported as lineTooLong"
+	MaxRedirectsHelp             = "Most redirects followed to fetch an http(s) input, 0 follows none"
+	MetricsPortHelp              = "Port to serve Prometheus metrics on at /metrics while validating, 0 for none"
+	NormalizedFieldsHelp         = "Top-level fields checked by --require-utf8-normalized, all string fields when empty"
+	OutputInvalidHelp            = "JSONL file that receives each line that fails validation, as read, the rejects of --output-valid"
+	OutputValidHelp              = "JSONL file that receives each line that passes validation, as read, making a cleaned copy of the input"
+	ParallelGzipHelp             = "Decompress gzip inputs with pgzip, this many 1 MiB blocks ahead of the validation and with the checksum on other cores, 0 uses the standard library reader"
+	PrettyHelp                   = "Write the records of --output-valid indented over several lines, their fields in the order read"
+	ProgressHelp                 = "Periodically print progress, with an approximate ETA when the input size is known, to stderr"
+	ProgressIntervalHelp         = "Seconds between the status lines of --progress"
+	ProxyHelp                    = "Proxy URL for http(s) inputs and schemas, overriding HTTP_PROXY, HTTPS_PROXY and NO_PROXY"
+	QuietHelp                    = "Print no per-line messages, only the summary"
+	RecordIdFieldHelp            = "Field read as the RECORD_ID of records that have no RECORD_ID, like id"
+	RecursiveHelp                = "When --input-url is a directory, also validate the files in its subdirectories"
+	ReportDirHelp                = "Directory where a JSON summary is written for each input, and aggregate.json for all of them when there are several"
+	ReportFormatHelp             = "Format of the summary on stdout, text, json or csv, with json or csv the other messages go to stderr"
+	RequireFieldHelp             = "Top-level key every record must have, repeatable, alternatives separated by | as in NAME_FULL|NAME_ORG"
+	RequireGroupedDataSourceHelp = "Flag records whose DATA_SOURCE reappears after a different DATA_SOURCE"
+	RequireUTF8NormalizedHelp    = "Flag records with text fields that are not in Unicode NFC form"
+	ResumeHelp                   = "Continue from the --checkpoint of an interrupted run instead of starting over"
+	RewriteMappedFieldsHelp      = "Write records to the outputs with the --record-id-field and --data-source-field renamed to RECORD_ID and DATA_SOURCE"
+	SampleHelp                   = "Validate only the first N non-blank lines of each input, 0 for all of them"
+	SampleRateHelp               = "Fraction of the non-blank lines, chosen at random, that are validated"
+	SchemaHelp                   = "JSON Schema file or http(s) URL each record must conform to"
+	SeedHelp                     = "Seed for the --sample-rate random choice, so a run can be repeated, time based when 0"
+	SpecVersionHelp              = "Version of the Generic Entity Specification records are validated against, 2 or 3"
+	SplitBadFileHelp             = "File that receives the invalid lines when --split-output-dir is given"
+	SplitOutputDirHelp           = "Directory where each valid record is written to <DATA_SOURCE>.jsonl, a -2, -3, ... suffix tells apart codes with the same file name"
+	SqliteOutHelp                = "SQLite database file that receives a row for every validated line"
+	StartLineHelp                = "First line of each input that is validated, the lines before it are only counted for numbering"
+	StateFileHelp                = "JSON file recording the ETag of each http(s), S3, GCS and Azure input that validated cleanly, unchanged inputs are skipped, other inputs are always read"
+	StrictHelp                   = "Flag records with top-level keys that are not in the Generic Entity Specification"
+	SuggestFixesHelp             = "For lines that fail the base checks, report which safe normalizations would make them pass"
+	SummaryOnlyOnErrorHelp       = "Print nothing when every line is valid, the usual summary and per-line messages only when something is wrong"
+	TimeoutHelp                  = "Seconds the whole run may take, after which it stops, reports what was validated so far and exits with 4, 0 for no limit"
+	VerboseHelp                  = "Also print the DATA_SOURCE and RECORD_ID of each valid record"
+	WatchIntervalHelp            = "Seconds to wait between fetches in --watch-remote mode"
+	WatchRemoteHelp              = "Keep re-fetching an append-only http(s) JSONL resource and validate newly appended lines"
+	WorkersHelp                  = "Number of goroutines that validate lines concurrently"
+	ZipPasswordHelp              = "Password for encrypted (AES or ZipCrypto) zip entries"
+)
+
+// exitStatus is the outcome of a run.  Each has a stable exit code, and a
+// worse outcome has a higher value.
+type exitStatus int
+
+// The outcomes of a run, their value is the exit code.
+const (
+	// 0, every line of every input was valid
+	statusClean exitStatus = iota
+	// 1, unless --error-exit-code is given, some lines were bad
+	statusBadLines
+	// 2, an input couldn't be read, or only part of a corrupt or truncated
+	// input could be validated
+	statusInputError
+	// 3, an option or argument was wrong
+	statusUsageError
+	// 4, the --timeout passed before the run finished
+	statusTimedOut
+)
+
+// The outcome of the run so far, see raiseStatus.
+var runStatus exitStatus
+
 // validate is 6203:  https://github.com/Senzing/knowledge-base/blob/main/lists/senzing-product-ids.md
 const MessageIdFormat = "senzing-6203%04d"
 
@@ -46,7 +318,7 @@ var (
 // ----------------------------------------------------------------------------
 // rootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Use:   "validate",
+	Use:   "validate [file...]",
 	Short: "Validates a JSON-lines file.",
 	Long: `
 	Welcome to validate!
@@ -56,99 +328,412 @@ var RootCmd = &cobra.Command{
 
 	validate --input-url "file:///path/to/json/lines/file.jsonl"
 	validate --input-url "https://public-read-access.s3.amazonaws.com/TestDataSets/SenzingTruthSet/truth-set-3.0.0.jsonl"
+	validate ~/data/file.jsonl ./more.jsonl.gz
+
+	Exit codes:
+
+	0  every line of every input was valid
+	1  some lines were bad, or the --error-exit-code
+	2  an input couldn't be read, or was corrupt or truncated partway
+	3  an option or argument was wrong
+	4  the --timeout passed before the run finished
 	`,
+	Args: cobra.ArbitraryArgs,
 	PreRun: func(cobraCommand *cobra.Command, args []string) {
 		loadConfigurationFile(cobraCommand)
 		loadOptions(cobraCommand)
 		cobraCommand.SetVersionTemplate(constant.VersionTemplate)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		switch viper.GetString(ReportFormat) {
+		case reportFormatJSON, reportFormatCSV:
+			// keep stdout for the JSON or CSV summaries
+			output = newSyncWriter(os.Stderr)
+			cmd.SetOut(os.Stderr)
+		case reportFormatText:
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown --%s %s, use %s, %s or %s.\n", ReportFormat, viper.GetString(ReportFormat), reportFormatText, reportFormatJSON, reportFormatCSV)
+			raiseStatus(statusUsageError)
+			return
+		}
+		if !openLogFile() {
+			raiseStatus(statusUsageError)
+			return
+		}
+		defer closeLogFile()
+		defer output.Flush()
+		if !setLogFormat() || !setColor() {
+			raiseStatus(statusUsageError)
+			return
+		}
+		setLogLevel(cmd)
+		if viper.GetBool(SummaryOnlyOnError) {
+			output.hold()
+			defer output.discard()
+		}
 
-		if !read() {
+		if len(args) > 0 {
+			viper.Set(option.InputURL, append(viper.GetStringSlice(option.InputURL), fileURLs(args)...))
+		}
+		if viper.GetBool(DebugClassification) && !debugClassification() {
+			output.Println("Some errors are not classified as expected, check the go-common version.")
+		}
+		defer startTimeout()()
+		status := read()
+		raiseStatus(status)
+		writeAggregateReport()
+		if status == statusUsageError {
+			output.Flush()
 			cmd.Help()
 		}
-
+		if badLines > 0 {
+			raiseStatus(statusBadLines)
+		}
+		if timedOut.Load() {
+			raiseStatus(statusTimedOut)
+		}
 	},
 }
 
 // ----------------------------------------------------------------------------
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
+// Returns the exit code of the run.
+func Execute() int {
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		return exitCodeOf(statusUsageError)
+	}
+	return exitCodeOf(runStatus)
+}
+
+// ----------------------------------------------------------------------------
+
+// Record an outcome of the run, unless a worse one already was.  Output held
+// back by --summary-only-on-error is written once something is wrong.
+func raiseStatus(status exitStatus) {
+	if status > runStatus {
+		runStatus = status
+	}
+	if runStatus != statusClean {
+		output.release()
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Read an input with the outcome of the run so far set aside, returning the
+// worst status raised while reading it, which is then added to the run.
+func readWithStatus(read func() bool) (bool, exitStatus) {
+	before := runStatus
+	runStatus = statusClean
+	ok := read()
+	status := runStatus
+	runStatus = max(before, status)
+	return ok, status
+}
+
+// ----------------------------------------------------------------------------
+
+// The exit code of an outcome, with --error-exit-code for bad lines.
+func exitCodeOf(status exitStatus) int {
+	if status == statusBadLines && viper.IsSet(ErrorExitCode) {
+		return viper.GetInt(ErrorExitCode)
 	}
+	return int(status)
 }
 
 // ----------------------------------------------------------------------------
-func read() bool {
 
-	inputURL := viper.GetString(option.InputURL)
-	inputURLLen := len(inputURL)
+// Validate the --input-url inputs.  Returns statusUsageError when an option
+// or an input URL is wrong, statusInputError when an input couldn't be read.
+func read() exitStatus {
 
-	if inputURLLen == 0 {
+	inputURLs := viper.GetStringSlice(option.InputURL)
+	if viper.GetBool(DryRun) {
+		if len(inputURLs) == 0 {
+			inputURLs = []string{""}
+		}
+		for _, inputURL := range inputURLs {
+			if !dryRun(inputURL) {
+				return statusUsageError
+			}
+		}
+		return statusClean
+	}
+	if !openHTTPClient() || !checkLineRange() {
+		return statusUsageError
+	}
+	if !loadSpec() {
+		return statusUsageError
+	}
+	if !loadSchema() {
+		return statusUsageError
+	}
+	if !loadFeatures() {
+		return statusUsageError
+	}
+	if !openMetrics() {
+		return statusUsageError
+	}
+	if !openSampler() {
+		return statusUsageError
+	}
+	if !openEncoding() {
+		return statusUsageError
+	}
+	if !openCheckpoint() {
+		return statusUsageError
+	}
+	if !openSinks() {
+		return statusUsageError
+	}
+	defer closeSinks()
+
+	if len(inputURLs) <= 1 {
+		inputURL := ""
+		if len(inputURLs) == 1 {
+			inputURL = inputURLs[0]
+		}
+		return readInputURL(inputURL)
+	}
+	inputs, failed := len(inputURLs), 0
+	linesBefore, badBefore := totalLines, badLines
+	status := statusClean
+	for i, inputURL := range inputURLs {
+		if inputStatus := readInputURL(inputURL); inputStatus != statusClean {
+			failed++
+			status = max(status, inputStatus)
+		}
+		if stopRun() {
+			inputs = i + 1
+			break
+		}
+	}
+	output.Println(passFail(badLines-badBefore, fmt.Sprintf("Validated %d inputs, %d lines in total, %d were bad.", inputs, totalLines-linesBefore, badLines-badBefore)))
+	if failed > 0 {
+		output.Printf("%d input(s) could not be read.\n", failed)
+	}
+	return status
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate one --input-url, stdin when it is empty or "-".
+func readInputURL(inputURL string) exitStatus {
+	if len(inputURL) == 0 || inputURL == "-" {
 		//assume stdin
-		return readStdin()
+		return readStatus("stdin", readStdin(inputURL == "-"))
 	}
 
 	//This assumes the URL includes a schema and path so, minimally:
 	//  "s://p" where the schema is 's' and 'p' is the complete path
 	if len(inputURL) < 5 {
 		logger.LogMessage(MessageIdFormat, 2002, fmt.Sprintf("Check the inputURL parameter: %s", inputURL))
-		return false
+		return statusUsageError
 	}
 
 	fileType := viper.GetString(option.InputFileType)
 	logger.LogMessage(MessageIdFormat, 2, fmt.Sprintf("Validating URL string: %s", inputURL))
-	fmt.Println("inputURL:", inputURL)
+	output.Println("inputURL:", inputURL)
 	u, err := url.Parse(inputURL)
 	if err != nil {
 		logger.LogMessageFromError(MessageIdFormat, 9001, "Fatal error parsing inputURL.", err)
-		return false
+		return statusUsageError
+	}
+	var read bool
+	if viper.GetBool(Follow) {
+		if !followable(u) {
+			return statusUsageError
+		}
+		return readStatus(redactURL(inputURL), followFile(filePath(u), fileType))
 	}
 	if u.Scheme == "file" {
-		if strings.HasSuffix(u.Path, "jsonl") || strings.ToUpper(fileType) == "JSONL" {
-			logger.LogMessage(MessageIdFormat, 3, "Validating as a JSONL file.")
-			return readJSONLFile(u.Path)
-		} else if strings.HasSuffix(u.Path, "gz") || strings.ToUpper(fileType) == "GZ" {
-			logger.LogMessage(MessageIdFormat, 4, "Validating a GZ file.")
-			return readGZFile(u.Path)
+		path := filePath(u)
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			read = readDirectory(path)
+		} else if err != nil && strings.ContainsAny(path, "*?[") {
+			read = readGlob(path, fileType)
 		} else {
-			logger.LogMessage(MessageIdFormat, 2003, "If this is a valid JSONL file, please rename with the .jsonl extension or use the file type override (--fileType).")
+			read = readFile(path, fileType)
 		}
 	} else if u.Scheme == "http" || u.Scheme == "https" {
-		fmt.Println("scheme:", u.Scheme)
-		if strings.HasSuffix(u.Path, "jsonl") || strings.ToUpper(fileType) == "JSONL" {
-			logger.LogMessage(MessageIdFormat, 5, "Validating as a JSONL resource.")
-			fmt.Println("validate jsonl")
-			return readJSONLResource(inputURL)
-		} else if strings.HasSuffix(u.Path, "gz") || strings.ToUpper(fileType) == "GZ" {
-			fmt.Println("validate gz")
-			logger.LogMessage(MessageIdFormat, 6, "Validating a GZ resource.")
-			return readGZResource(inputURL)
-		} else {
-			fmt.Println("ugh")
-			logger.LogMessage(MessageIdFormat, 2004, "If this is a valid JSONL file, please rename with the .jsonl extension or use the file type override (--fileType).")
-		}
+		output.Println("scheme:", u.Scheme)
+		read = readUnlessUnchanged(u, func() bool { return readResource(inputURL, u, fileType) })
+	} else if u.Scheme == "s3" {
+		logger.LogMessage(MessageIdFormat, 28, "Validating an S3 object.")
+		read = readS3Object(u)
+	} else if u.Scheme == "gs" {
+		logger.LogMessage(MessageIdFormat, 29, "Validating a GCS object.")
+		read = readGCSObject(u)
+	} else if u.Scheme == "az" {
+		logger.LogMessage(MessageIdFormat, 42, "Validating an Azure blob.")
+		read = readAzureBlob(u)
+	} else if u.Scheme == "sftp" {
+		logger.LogMessage(MessageIdFormat, 41, "Validating an SFTP file.")
+		read = readSFTPFile(u)
+	} else if u.Scheme == "ftp" {
+		logger.LogMessage(MessageIdFormat, 56, "Validating an FTP file.")
+		read = readFTPFile(u)
+	} else if u.Scheme == "kafka" {
+		logger.LogMessage(MessageIdFormat, 25, "Validating the messages of a Kafka topic.")
+		read = readKafkaTopic(u)
 	} else {
 		logger.LogMessage(MessageIdFormat, 9002, fmt.Sprintf("We don't handle %s input URLs.", u.Scheme))
+		return statusUsageError
+	}
+	return readStatus(redactURL(inputURL), read)
+}
+
+// ----------------------------------------------------------------------------
+
+// The outcome of reading an input.  The readers log why an input couldn't
+// be read, and the logs are off by default, so this says where to look.
+func readStatus(source string, read bool) exitStatus {
+	if read {
+		return statusClean
+	}
+	output.Printf("Unable to read %s, --%s error logs the reason.\n", source, option.LogLevel)
+	return statusInputError
+}
+
+// ----------------------------------------------------------------------------
+
+// Read a local file, picking the reader from the file type: the
+// --input-file-type override, else a guess from the leading bytes, else the
+// suffix.
+func readFile(path string, fileType string) bool {
+	fileType = strings.ToUpper(fileType)
+	if len(fileType) == 0 {
+		fileType = sniffFile(path)
+	}
+	if len(fileType) == 0 {
+		fileType = fileTypeOf(path)
+	}
+	// a file starting with '[' is read as JSONL when --input-format says so
+	if fileType == "JSONL" || (fileType == "JSON" && viper.IsSet(InputFormat) && viper.GetString(InputFormat) == inputFormatJSONL) {
+		logger.LogMessage(MessageIdFormat, 3, "Validating as a JSONL file.")
+		return readJSONLFile(path)
+	} else if fileType == "JSON" {
+		logger.LogMessage(MessageIdFormat, 46, "Validating as a JSON array file.")
+		return readJSONFile(path)
+	} else if fileType == "GZ" {
+		logger.LogMessage(MessageIdFormat, 4, "Validating a GZ file.")
+		return readGZFile(path)
+	} else if fileType == "ZIP" {
+		logger.LogMessage(MessageIdFormat, 17, "Validating a ZIP file.")
+		return readZipFile(path)
+	} else if fileType == "TAR" {
+		logger.LogMessage(MessageIdFormat, 55, "Validating a TAR file.")
+		return readTarFile(path)
+	} else if fileType == "PARQUET" {
+		logger.LogMessage(MessageIdFormat, 60, "Validating a PARQUET file.")
+		return readParquetFile(path)
+	} else if fileType == "LZ4" {
+		logger.LogMessage(MessageIdFormat, 22, "Validating an LZ4 file.")
+		return readCompressedFile(path, "LZ4")
+	} else if fileType == "BZ2" {
+		logger.LogMessage(MessageIdFormat, 32, "Validating a BZ2 file.")
+		return readCompressedFile(path, "BZ2")
+	} else if fileType == "ZST" {
+		logger.LogMessage(MessageIdFormat, 34, "Validating a ZST file.")
+		return readCompressedFile(path, "ZST")
+	} else {
+		logger.LogMessage(MessageIdFormat, 2003, "If this is a valid JSONL file, please rename with the .jsonl extension or use the file type override (--fileType).")
 	}
 	return false
 }
 
+// ----------------------------------------------------------------------------
+
+// Read an http(s) input, picking the reader from the file type.  Without the
+// --input-file-type override, the type is detected from the response, except
+// for --watch-remote which goes by the name's suffix.
+func readResource(inputURL string, u *url.URL, fileType string) bool {
+	name := resourceName(u)
+	if len(fileType) == 0 && !viper.GetBool(WatchRemote) {
+		logger.LogMessage(MessageIdFormat, 21, "Detecting the resource type from the response.")
+		return readDetectedResource(inputURL, name)
+	}
+	fileType = strings.ToUpper(fileType)
+	if len(fileType) == 0 {
+		fileType = fileTypeOf(name)
+	}
+	if fileType == "JSONL" {
+		logger.LogMessage(MessageIdFormat, 5, "Validating as a JSONL resource.")
+		output.Println("validate jsonl")
+		if viper.GetBool(WatchRemote) {
+			return watchJSONLResource(inputURL)
+		}
+		return readJSONLResource(inputURL)
+	} else if fileType == "GZ" {
+		output.Println("validate gz")
+		logger.LogMessage(MessageIdFormat, 6, "Validating a GZ resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readGZResource(inputURL)
+	} else if fileType == "ZIP" {
+		output.Println("validate zip")
+		logger.LogMessage(MessageIdFormat, 36, "Validating a ZIP resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readZipResource(inputURL)
+	} else if fileType == "PARQUET" {
+		output.Println("validate parquet")
+		logger.LogMessage(MessageIdFormat, 59, "Validating a PARQUET resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readParquetResource(inputURL)
+	} else if fileType == "LZ4" {
+		output.Println("validate lz4")
+		logger.LogMessage(MessageIdFormat, 23, "Validating an LZ4 resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readCompressedResource(inputURL, "LZ4")
+	} else if fileType == "BZ2" {
+		output.Println("validate bz2")
+		logger.LogMessage(MessageIdFormat, 33, "Validating a BZ2 resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readCompressedResource(inputURL, "BZ2")
+	} else if fileType == "ZST" {
+		output.Println("validate zst")
+		logger.LogMessage(MessageIdFormat, 35, "Validating a ZST resource.")
+		if viper.GetBool(WatchRemote) {
+			logger.LogMessage(MessageIdFormat, 2006, "The --watch-remote option only supports uncompressed JSONL resources.")
+			return false
+		}
+		return readCompressedResource(inputURL, "ZST")
+	} else {
+		logger.LogMessage(MessageIdFormat, 21, "Detecting the resource type from the response.")
+		return readDetectedResource(inputURL, name)
+	}
+}
+
 // ----------------------------------------------------------------------------
 func readJSONLResource(jsonURL string) bool {
-	response, err := http.Get(jsonURL)
+	response, err := getResource(jsonURL)
 
 	if err != nil {
-		fmt.Println("unable to get:", jsonURL)
+		output.Println("unable to get:", jsonURL)
 		logger.LogMessageFromError(MessageIdFormat, 9003, "Fatal error retrieving inputURL.", err)
 		return false
 	}
 	defer response.Body.Close()
-	validateLines(response.Body)
-	return true
+	body := &countingReader{reader: response.Body}
+	validateLines(jsonURL, trackProgress(body, response.ContentLength))
+	return checkContentLength(response, body)
 }
 
 // ----------------------------------------------------------------------------
@@ -159,24 +744,46 @@ func readJSONLFile(jsonFile string) bool {
 		return false
 	}
 	defer file.Close()
-	validateLines(file)
+	seekToCheckpoint(file, jsonFile)
+	validateLines(jsonFile, trackProgress(file, fileSize(file)))
 	return true
 }
 
 // ----------------------------------------------------------------------------
-func readStdin() bool {
-	info, err := os.Stdin.Stat()
+
+// Validate the elements of a file holding a single JSON array.
+func readJSONFile(jsonFile string) bool {
+	file, err := os.Open(jsonFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9004, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer file.Close()
+	validateArray(jsonFile, trackProgress(file, fileSize(file)))
+	return true
+}
+
+// ----------------------------------------------------------------------------
+// Read stdin when it is piped or redirected.  Without an explicit
+// --input-url -, an interactive terminal is taken to mean no input was given.
+// Compressed input is recognized by its leading bytes, or --input-file-type,
+// anything else is validated as JSONL.
+func readStdin(explicit bool) bool {
+	_, err := os.Stdin.Stat()
 	if err != nil {
 		logger.LogMessageFromError(MessageIdFormat, 9005, "Fatal error opening stdin.", err)
 		return false
 	}
 	//printFileInfo(info)
 
-	if info.Mode()&os.ModeNamedPipe == os.ModeNamedPipe {
+	if explicit || !term.IsTerminal(int(os.Stdin.Fd())) {
 
-		reader := bufio.NewReader(os.Stdin)
-		validateLines(reader)
-		return true
+		reader := bufio.NewReader(trackProgress(os.Stdin, fileSize(os.Stdin)))
+		stdinType := streamType("", viper.GetString(option.InputFileType), reader)
+		if len(stdinType) == 0 {
+			stdinType = "JSONL"
+		}
+		return validateTypedStream("stdin", stdinType, reader)
 	}
 	logger.LogMessageFromError(MessageIdFormat, 9006, "Fatal error stdin not piped.", err)
 	return false
@@ -184,20 +791,21 @@ func readStdin() bool {
 
 // ----------------------------------------------------------------------------
 func readGZResource(gzURL string) bool {
-	response, err := http.Get(gzURL)
+	response, err := getResource(gzURL)
 	if err != nil {
 		logger.LogMessageFromError(MessageIdFormat, 9009, "Fatal error retrieving inputURL.", err)
 		return false
 	}
 	defer response.Body.Close()
-	reader, err := gzip.NewReader(response.Body)
+	body := &countingReader{reader: response.Body}
+	reader, err := newGzipReader(trackProgress(body, response.ContentLength))
 	if err != nil {
 		logger.LogMessageFromError(MessageIdFormat, 9010, "Fatal error reading inputURL.", err)
 		return false
 	}
 	defer reader.Close()
-	validateLines(reader)
-	return true
+	validateCompressed(gzURL, "GZ", reader)
+	return checkContentLength(response, body)
 }
 
 // ----------------------------------------------------------------------------
@@ -211,67 +819,202 @@ func readGZFile(gzFile string) bool {
 	}
 	defer gzipfile.Close()
 
-	reader, err := gzip.NewReader(gzipfile)
+	reader, err := newGzipReader(trackProgress(gzipfile, fileSize(gzipfile)))
 	if err != nil {
 		logger.LogMessageFromError(MessageIdFormat, 9008, "Fatal error reading inputURL.", err)
 		return false
 	}
 	defer reader.Close()
-	validateLines(reader)
+	validateCompressed(gzFile, "GZ", reader)
 	return true
 }
 
 // ----------------------------------------------------------------------------
-func validateLines(reader io.Reader) {
-	scanner := bufio.NewScanner(reader)
-	totalLines := 0
-	noRecordId := 0
-	noDataSource := 0
-	malformed := 0
-	badRecord := 0
-	for scanner.Scan() {
-		totalLines++
-		str := strings.TrimSpace(scanner.Text())
-		// ignore blank lines
-		if len(str) > 0 {
-			valid, err := record.Validate(str)
-			if !valid {
-				fmt.Println("Line", totalLines, err)
-				if err != nil {
-					if strings.Contains(err.Error(), "RECORD_ID") {
-						noRecordId++
-					} else if strings.Contains(err.Error(), "DATA_SOURCE") {
-						noDataSource++
-					} else if strings.Contains(err.Error(), "not well formed") {
-						malformed++
-					} else {
-						badRecord++
-					}
-				}
-			}
-		}
+
+// Validate the lines of a stream and report them.  A stream that fails
+// partway, like a dropped connection, a disk read error or a zip entry
+// failing its checksum, is a fatal read error rather than a clean end.
+func validateLines(source string, reader io.Reader) {
+	if err := scanLines(source, reader); err != nil {
+		readFailed(source, err)
 	}
-	if noRecordId > 0 {
-		logger.LogMessage(MessageIdFormat, 5, fmt.Sprintf("%d line(s) had no RECORD_ID field.", noRecordId))
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate and report the lines of a stream, or its elements when it holds a
+// JSON array.  Returns the error that stopped the stream before its end, the
+// summary is then marked incomplete and the --checkpoint is kept for a
+// --resume.
+func scanLines(source string, reader io.Reader) error {
+	// a decompressed stream holding a tar archive, as a .tar.gz does, has
+	// its members validated
+	raw := bufio.NewReader(reader)
+	if sniffFileType(raw) == "TAR" {
+		validateTarArchive(source, raw)
+		return nil
+	}
+	// a JSON-line can't start with '[', so a decompressed stream that does
+	// is taken to be a JSON array, unless the --input-format says otherwise
+	buffered := bufio.NewReader(decodeInput(raw))
+	if viper.GetString(InputFormat) == inputFormatJSONArray || (!viper.IsSet(InputFormat) && sniffFileType(buffered) == "JSON") {
+		validateArray(source, buffered)
+		return nil
+	}
+	result := newSummary(source)
+	scanner, splitter := newLineScanner(buffered)
+	if resume := resumeFrom(source); resume != nil {
+		resume.restore(result, splitter)
+	}
+	validateScanner(scanner, splitter, result)
+	err := scanner.Err()
+	if err != nil && timedOut.Load() {
+		// the --timeout canceled the read
+		result.StoppedEarly, result.TimedOut = true, true
+		err = nil
+	}
+	if err != nil {
+		result.Incomplete = true
+	} else if !result.TimedOut {
+		clearCheckpoint()
+	}
+	result.report()
+	inputProgress = nil
+	return err
+}
+
+// ----------------------------------------------------------------------------
+
+// The size of an open file, -1 when it can't be determined.
+func fileSize(file *os.File) int64 {
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return -1
 	}
-	if noDataSource > 0 {
-		logger.LogMessage(MessageIdFormat, 6, fmt.Sprintf("%d line(s) had no DATA_SOURCE field.", noDataSource))
+	return info.Size()
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate each line from the scanner, accumulating counts into result.  The
+// splitter, when not nil, is the scanner's and reports lines too long to
+// validate.
+func validateScanner(scanner *bufio.Scanner, splitter *jsonl.Splitter, result *summary) {
+	checks := newLineChecks(result)
+	checks.splitter = splitter
+	// skip over the lines validated before a --resume
+	for skipped := 0; skipped < result.skipLines && scanner.Scan(); skipped++ {
 	}
-	if malformed > 0 {
-		logger.LogMessage(MessageIdFormat, 7, fmt.Sprintf("%d line(s) are not well formed JSON-lines.", malformed))
+	if checks.workers > 1 {
+		validateConcurrently(scanner, checks, result)
+		return
 	}
-	if badRecord > 0 {
-		logger.LogMessage(MessageIdFormat, 8, fmt.Sprintf("%d line(s) did not validate for an unknown reason.", badRecord))
+	for scanner.Scan() {
+		line := checks.prepareScanned(scanner)
+		checks.checkRecord(line)
+		if !checks.finish(result, line) {
+			break
+		}
 	}
-	logger.LogMessage(MessageIdFormat, 9, fmt.Sprintf("Validated %d lines, %d were bad.", totalLines, noRecordId+noDataSource+malformed+badRecord))
-	fmt.Printf("Validated %d lines, %d were bad.\n", totalLines, noRecordId+noDataSource+malformed+badRecord)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the next line of a stream, accumulating its outcome into result.
+// The line is trimmed in place, not copied, so it need only stay valid until
+// this returns.  Returns false once --max-errors is reached and the stream
+// should be abandoned.
+func validateLine(checks *lineChecks, result *summary, raw []byte) bool {
+	line := checks.prepareBytes(bytes.TrimSpace(raw), "")
+	checks.checkRecord(line)
+	return checks.finish(result, line)
 }
 
 // ----------------------------------------------------------------------------
 func init() {
 	RootCmd.Flags().String(option.InputFileType, defaultFileType, option.InputFileTypeHelp)
-	RootCmd.Flags().String(option.InputURL, defaultInputURL, option.InputURLHelp)
+	RootCmd.Flags().StringArray(option.InputURL, defaultInputURL, option.InputURLHelp+", repeat it to validate several inputs in turn")
 	RootCmd.Flags().String(option.LogLevel, defaultLogLevel, fmt.Sprintf(option.LogLevelHelp, envar.LogLevel))
+	RootCmd.Flags().StringSlice(AllowedDataSource, defaultAllowedDataSource, AllowedDataSourceHelp)
+	RootCmd.Flags().String(AzureStorageAccount, defaultAzureStorageAccount, AzureStorageAccountHelp)
+	RootCmd.Flags().Bool(CheckDuplicates, defaultCheckDuplicates, CheckDuplicatesHelp)
+	RootCmd.Flags().String(Checkpoint, defaultCheckpoint, CheckpointHelp)
+	RootCmd.Flags().String(Color, defaultColor, ColorHelp)
+	RootCmd.Flags().String(CompareSchema, defaultCompareSchema, CompareSchemaHelp)
+	RootCmd.Flags().String(CompareSpecVersion, defaultCompareSpecVersion, CompareSpecVersionHelp)
+	RootCmd.Flags().Bool(CountOnly, defaultCountOnly, CountOnlyHelp)
+	RootCmd.Flags().String(DataSourceField, defaultDataSourceField, DataSourceFieldHelp)
+	RootCmd.Flags().Bool(DataSourceStats, defaultDataSourceStats, DataSourceStatsHelp)
+	RootCmd.Flags().Bool(DebugClassification, defaultDebugClassification, DebugClassificationHelp)
+	RootCmd.Flags().Bool(DryRun, defaultDryRun, DryRunHelp)
+	RootCmd.Flags().String(Encoding, defaultEncoding, EncodingHelp)
+	RootCmd.Flags().Int(EndLine, defaultEndLine, EndLineHelp)
+	RootCmd.Flags().Int(ErrorExitCode, defaultErrorExitCode, ErrorExitCodeHelp)
+	RootCmd.Flags().String(ErrorFile, defaultErrorFile, ErrorFileHelp)
+	RootCmd.Flags().Int(ExamplesPerCategory, defaultExamplesPerCategory, ExamplesPerCategoryHelp)
+	RootCmd.Flags().Bool(FailFast, defaultFailFast, FailFastHelp)
+	RootCmd.Flags().String(FeaturesConfig, defaultFeaturesConfig, FeaturesConfigHelp)
+	RootCmd.Flags().Bool(Follow, defaultFollow, FollowHelp)
+	RootCmd.Flags().Int(FollowTimeout, defaultFollowTimeout, FollowTimeoutHelp)
+	RootCmd.Flags().Bool(GzipMultistream, defaultGzipMultistream, GzipMultistreamHelp)
+	RootCmd.Flags().StringArray(Header, defaultHeader, HeaderHelp)
+	RootCmd.Flags().Int(HistogramBuckets, defaultHistogramBuckets, HistogramBucketsHelp)
+	RootCmd.Flags().String(HttpBody, defaultHttpBody, HttpBodyHelp)
+	RootCmd.Flags().String(HttpBodyFile, defaultHttpBodyFile, HttpBodyFileHelp)
+	RootCmd.Flags().String(HttpContentType, defaultHttpContentType, HttpContentTypeHelp)
+	RootCmd.Flags().String(HttpMethod, defaultHttpMethod, HttpMethodHelp)
+	RootCmd.Flags().Int(HttpTimeout, defaultHttpTimeout, HttpTimeoutHelp)
+	RootCmd.Flags().String(IdentityFile, defaultIdentityFile, IdentityFileHelp)
+	RootCmd.Flags().StringSlice(IgnoreFields, defaultIgnoreFields, IgnoreFieldsHelp)
+	RootCmd.Flags().String(InputFormat, defaultInputFormat, InputFormatHelp)
+	RootCmd.Flags().String(KafkaGroup, defaultKafkaGroup, KafkaGroupHelp)
+	RootCmd.Flags().Int(KafkaIdleTimeout, defaultKafkaIdleTimeout, KafkaIdleTimeoutHelp)
+	RootCmd.Flags().String(KafkaStartOffset, defaultKafkaStartOffset, KafkaStartOffsetHelp)
+	RootCmd.Flags().String(KnownHosts, defaultKnownHosts, KnownHostsHelp)
+	RootCmd.Flags().String(LogFile, defaultLogFile, LogFileHelp)
+	RootCmd.Flags().Bool(LogFileAppend, defaultLogFileAppend, LogFileAppendHelp)
+	RootCmd.Flags().String(LogFormat, defaultLogFormat, LogFormatHelp)
+	RootCmd.Flags().Int(MaxErrors, defaultMaxErrors, MaxErrorsHelp)
+	RootCmd.Flags().Int(MaxLineBytes, defaultMaxLineBytes, MaxLineBytesHelp)
+	RootCmd.Flags().Int(MaxRedirects, defaultMaxRedirects, MaxRedirectsHelp)
+	RootCmd.Flags().Int(MetricsPort, defaultMetricsPort, MetricsPortHelp)
+	RootCmd.Flags().StringSlice(NormalizedFields, defaultNormalizedFields, NormalizedFieldsHelp)
+	RootCmd.Flags().String(OutputInvalid, defaultOutputInvalid, OutputInvalidHelp)
+	RootCmd.Flags().String(OutputValid, defaultOutputValid, OutputValidHelp)
+	RootCmd.Flags().Int(ParallelGzip, defaultParallelGzip, ParallelGzipHelp)
+	RootCmd.Flags().Bool(Pretty, defaultPretty, PrettyHelp)
+	RootCmd.Flags().Bool(Progress, defaultProgress, ProgressHelp)
+	RootCmd.Flags().Int(ProgressInterval, defaultProgressInterval, ProgressIntervalHelp)
+	RootCmd.Flags().String(Proxy, defaultProxy, ProxyHelp)
+	RootCmd.Flags().Bool(Quiet, defaultQuiet, QuietHelp)
+	RootCmd.Flags().String(RecordIdField, defaultRecordIdField, RecordIdFieldHelp)
+	RootCmd.Flags().Bool(Recursive, defaultRecursive, RecursiveHelp)
+	RootCmd.Flags().String(ReportDir, defaultReportDir, ReportDirHelp)
+	RootCmd.Flags().String(ReportFormat, defaultReportFormat, ReportFormatHelp)
+	RootCmd.Flags().StringSlice(RequireField, defaultRequireField, RequireFieldHelp)
+	RootCmd.Flags().Bool(RequireGroupedDataSource, defaultRequireGrouped, RequireGroupedDataSourceHelp)
+	RootCmd.Flags().Bool(RequireUTF8Normalized, defaultRequireUTF8Normalized, RequireUTF8NormalizedHelp)
+	RootCmd.Flags().Bool(Resume, defaultResume, ResumeHelp)
+	RootCmd.Flags().Bool(RewriteMappedFields, defaultRewriteMappedFields, RewriteMappedFieldsHelp)
+	RootCmd.Flags().Int(Sample, defaultSample, SampleHelp)
+	RootCmd.Flags().Float64(SampleRate, defaultSampleRate, SampleRateHelp)
+	RootCmd.Flags().String(Schema, defaultSchema, SchemaHelp)
+	RootCmd.Flags().Int64(Seed, defaultSeed, SeedHelp)
+	RootCmd.Flags().String(SpecVersion, defaultSpecVersion, SpecVersionHelp)
+	RootCmd.Flags().String(SplitBadFile, defaultSplitBadFile, SplitBadFileHelp)
+	RootCmd.Flags().String(SplitOutputDir, defaultSplitOutputDir, SplitOutputDirHelp)
+	RootCmd.Flags().String(SqliteOut, defaultSqliteOut, SqliteOutHelp)
+	RootCmd.Flags().Int(StartLine, defaultStartLine, StartLineHelp)
+	RootCmd.Flags().String(StateFile, defaultStateFile, StateFileHelp)
+	RootCmd.Flags().Bool(Strict, defaultStrict, StrictHelp)
+	RootCmd.Flags().Bool(SuggestFixes, defaultSuggestFixes, SuggestFixesHelp)
+	RootCmd.Flags().Bool(SummaryOnlyOnError, defaultSummaryOnlyOnError, SummaryOnlyOnErrorHelp)
+	RootCmd.Flags().Int(Timeout, defaultTimeout, TimeoutHelp)
+	RootCmd.Flags().Bool(Verbose, defaultVerbose, VerboseHelp)
+	RootCmd.Flags().Int(WatchInterval, defaultWatchInterval, WatchIntervalHelp)
+	RootCmd.Flags().Bool(WatchRemote, defaultWatchRemote, WatchRemoteHelp)
+	RootCmd.Flags().Int(Workers, defaultWorkers, WorkersHelp)
+	RootCmd.Flags().String(ZipPassword, defaultZipPassword, ZipPasswordHelp)
 }
 
 // ----------------------------------------------------------------------------
@@ -324,20 +1067,154 @@ func loadOptions(cobraCommand *cobra.Command) {
 
 	stringOptions := map[string]string{
 		option.InputFileType: defaultFileType,
-		option.InputURL:      defaultInputURL,
 		option.LogLevel:      defaultLogLevel,
+		AzureStorageAccount:  defaultAzureStorageAccount,
+		Checkpoint:           defaultCheckpoint,
+		Color:                defaultColor,
+		CompareSchema:        defaultCompareSchema,
+		CompareSpecVersion:   defaultCompareSpecVersion,
+		DataSourceField:      defaultDataSourceField,
+		Encoding:             defaultEncoding,
+		ErrorFile:            defaultErrorFile,
+		FeaturesConfig:       defaultFeaturesConfig,
+		HttpBody:             defaultHttpBody,
+		HttpBodyFile:         defaultHttpBodyFile,
+		HttpContentType:      defaultHttpContentType,
+		HttpMethod:           defaultHttpMethod,
+		IdentityFile:         defaultIdentityFile,
+		KafkaGroup:           defaultKafkaGroup,
+		KafkaStartOffset:     defaultKafkaStartOffset,
+		KnownHosts:           defaultKnownHosts,
+		LogFile:              defaultLogFile,
+		LogFormat:            defaultLogFormat,
+		OutputInvalid:        defaultOutputInvalid,
+		OutputValid:          defaultOutputValid,
+		Proxy:                defaultProxy,
+		RecordIdField:        defaultRecordIdField,
+		ReportDir:            defaultReportDir,
+		ReportFormat:         defaultReportFormat,
+		Schema:               defaultSchema,
+		SpecVersion:          defaultSpecVersion,
+		SplitBadFile:         defaultSplitBadFile,
+		SplitOutputDir:       defaultSplitOutputDir,
+		SqliteOut:            defaultSqliteOut,
+		StateFile:            defaultStateFile,
+		ZipPassword:          defaultZipPassword,
 	}
 	for optionKey, optionValue := range stringOptions {
 		viper.SetDefault(optionKey, optionValue)
 		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
 	}
+	// --input-format gets its default from the flag alone, so viper.IsSet
+	// tells whether it was given and the input is otherwise sniffed
+	viper.BindPFlag(InputFormat, cobraCommand.Flags().Lookup(InputFormat))
+
+	// Ints
+
+	intOptions := map[string]int{
+		EndLine:             defaultEndLine,
+		ErrorExitCode:       defaultErrorExitCode,
+		ExamplesPerCategory: defaultExamplesPerCategory,
+		FollowTimeout:       defaultFollowTimeout,
+		HistogramBuckets:    defaultHistogramBuckets,
+		HttpTimeout:         defaultHttpTimeout,
+		KafkaIdleTimeout:    defaultKafkaIdleTimeout,
+		MaxErrors:           defaultMaxErrors,
+		MaxLineBytes:        defaultMaxLineBytes,
+		MaxRedirects:        defaultMaxRedirects,
+		MetricsPort:         defaultMetricsPort,
+		ParallelGzip:        defaultParallelGzip,
+		ProgressInterval:    defaultProgressInterval,
+		Sample:              defaultSample,
+		StartLine:           defaultStartLine,
+		Timeout:             defaultTimeout,
+		WatchInterval:       defaultWatchInterval,
+		Workers:             defaultWorkers,
+	}
+	for optionKey, optionValue := range intOptions {
+		viper.SetDefault(optionKey, optionValue)
+		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
+	}
+
+	// Int64s
+
+	int64Options := map[string]int64{
+		Seed: defaultSeed,
+	}
+	for optionKey, optionValue := range int64Options {
+		viper.SetDefault(optionKey, optionValue)
+		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
+	}
+
+	// Floats
+
+	floatOptions := map[string]float64{
+		SampleRate: defaultSampleRate,
+	}
+	for optionKey, optionValue := range floatOptions {
+		viper.SetDefault(optionKey, optionValue)
+		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
+	}
+
+	// Bools
+
+	boolOptions := map[string]bool{
+		CheckDuplicates:          defaultCheckDuplicates,
+		CountOnly:                defaultCountOnly,
+		DataSourceStats:          defaultDataSourceStats,
+		DebugClassification:      defaultDebugClassification,
+		DryRun:                   defaultDryRun,
+		FailFast:                 defaultFailFast,
+		Follow:                   defaultFollow,
+		GzipMultistream:          defaultGzipMultistream,
+		LogFileAppend:            defaultLogFileAppend,
+		Pretty:                   defaultPretty,
+		Progress:                 defaultProgress,
+		Quiet:                    defaultQuiet,
+		Recursive:                defaultRecursive,
+		RequireGroupedDataSource: defaultRequireGrouped,
+		RequireUTF8Normalized:    defaultRequireUTF8Normalized,
+		Resume:                   defaultResume,
+		RewriteMappedFields:      defaultRewriteMappedFields,
+		Strict:                   defaultStrict,
+		SuggestFixes:             defaultSuggestFixes,
+		SummaryOnlyOnError:       defaultSummaryOnlyOnError,
+		Verbose:                  defaultVerbose,
+		WatchRemote:              defaultWatchRemote,
+	}
+	for optionKey, optionValue := range boolOptions {
+		viper.SetDefault(optionKey, optionValue)
+		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
+	}
+
+	// Slices
+
+	sliceOptions := map[string][]string{
+		AllowedDataSource: defaultAllowedDataSource,
+		Header:            defaultHeader,
+		IgnoreFields:      defaultIgnoreFields,
+		option.InputURL:   defaultInputURL,
+		NormalizedFields:  defaultNormalizedFields,
+		RequireField:      defaultRequireField,
+	}
+	for optionKey, optionValue := range sliceOptions {
+		viper.SetDefault(optionKey, optionValue)
+		viper.BindPFlag(optionKey, cobraCommand.Flags().Lookup(optionKey))
+	}
 
 }
 
 // ----------------------------------------------------------------------------
-func setLogLevel() {
+
+// Apply --log-level when it is given on the command line, in the environment
+// or in the configuration file.  Otherwise nothing is logged.
+func setLogLevel(cobraCommand *cobra.Command) {
 	var level logger.Level = logger.LevelError
-	if viper.IsSet("logLevel") {
+	given := viper.InConfig(option.LogLevel) || len(os.Getenv(envar.LogLevel)) > 0
+	if flag := cobraCommand.Flags().Lookup(option.LogLevel); flag != nil && flag.Changed {
+		given = true
+	}
+	if given {
 		switch strings.ToUpper(viper.GetString(option.LogLevel)) {
 		case logger.LevelDebugName:
 			level = logger.LevelDebug
@@ -0,0 +1,75 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/senzing/senzing-tools/option"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Stream an s3://bucket/key object into the validator.  Credentials come from
+// the standard AWS chain: environment, shared config and files, or an IAM
+// role.  The object type follows --input-file-type or the key's suffix, so
+// gzipped objects are decompressed.  With --state-file an object whose ETag
+// already validated cleanly is skipped.
+func readS3Object(u *url.URL) bool {
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if len(bucket) == 0 || len(key) == 0 {
+		logger.LogMessage(MessageIdFormat, 9030, fmt.Sprintf("Fatal error, an S3 inputURL needs a bucket and a key: %s", u.String()))
+		output.Println("An S3 inputURL looks like s3://bucket/path/file.jsonl")
+		return false
+	}
+	awsConfig, err := config.LoadDefaultConfig(runContext)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9031, "Fatal error loading the AWS configuration.", err)
+		output.Println("Unable to load the AWS configuration:", err)
+		return false
+	}
+	client := s3.NewFromConfig(awsConfig, func(options *s3.Options) {
+		// S3 compatible stores often don't send checksums, that's not worth a log line
+		options.DisableLogOutputChecksumValidationSkipped = true
+	})
+	object, err := client.GetObject(runContext, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9032, "Fatal error retrieving the S3 object.", err)
+		output.Println("Unable to get", u.String()+":", err)
+		return false
+	}
+	defer object.Body.Close()
+	return readUnlessStored(u, func() string { return aws.ToString(object.ETag) }, func() bool {
+		return readS3Body(u, key, object)
+	})
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the body of an S3 object.
+func readS3Body(u *url.URL, key string, object *s3.GetObjectOutput) bool {
+	size := int64(-1)
+	if object.ContentLength != nil {
+		size = *object.ContentLength
+	}
+	body := &countingReader{reader: object.Body}
+	reader := bufio.NewReader(trackProgress(body, size))
+	source := u.String()
+	if !validateTypedStream(source, streamType(key, viper.GetString(option.InputFileType), reader), reader) {
+		return false
+	}
+	return checkSize(size, body)
+}
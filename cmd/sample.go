@@ -0,0 +1,54 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// sampler picks the random subset of lines validated with --sample-rate.
+type sampler struct {
+	rate   float64
+	seed   int64
+	random *rand.Rand
+}
+
+// The sampler for this run, nil when every line is validated.
+var lineSampler *sampler
+
+// ----------------------------------------------------------------------------
+
+// Set up sampling from --sample-rate and --seed.  Without a seed, or with 0,
+// a time based one is used.  The seed is reported in the summary so the run
+// can be repeated.
+func openSampler() bool {
+	lineSampler = nil
+	rate := viper.GetFloat64(SampleRate)
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		logger.LogMessage(MessageIdFormat, 9024, fmt.Sprintf("Fatal error, --%s must be more than 0 and at most 1.", SampleRate))
+		output.Println("--"+SampleRate, "must be more than 0 and at most 1.")
+		return false
+	}
+	seed := viper.GetInt64(Seed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	lineSampler = &sampler{rate: rate, seed: seed, random: rand.New(rand.NewSource(seed))}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether the next non-blank line should be validated.
+func (s *sampler) keep() bool {
+	return s.random.Float64() < s.rate
+}
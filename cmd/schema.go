@@ -0,0 +1,115 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+	"github.com/spf13/viper"
+)
+
+// The JSON Schemas from --schema and --compare-schema, compiled once for the
+// run.
+var (
+	recordSchema  *jsonschema.Schema
+	compareSchema *jsonschema.Schema
+)
+
+// ----------------------------------------------------------------------------
+
+// Compile the JSON Schemas given by --schema and --compare-schema, either a
+// file path or an http(s) URL.  Any $ref is resolved relative to the schema's
+// location.
+func loadSchema() bool {
+	schemaURL := viper.GetString(Schema)
+	compareURL := viper.GetString(CompareSchema)
+	if len(compareURL) > 0 && len(schemaURL) == 0 {
+		logger.LogMessage(MessageIdFormat, 9027, "Fatal error, --"+CompareSchema+" needs --"+Schema+".")
+		output.Println("--"+CompareSchema, "needs --"+Schema, "to compare with.")
+		return false
+	}
+	if len(schemaURL) > 0 && recordSchema == nil {
+		if recordSchema = compileSchema(schemaURL); recordSchema == nil {
+			return false
+		}
+	}
+	if len(compareURL) > 0 && compareSchema == nil {
+		if compareSchema = compileSchema(compareURL); compareSchema == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Compile one JSON Schema, nil when it can't be.
+func compileSchema(schemaURL string) *jsonschema.Schema {
+	logger.LogMessage(MessageIdFormat, 14, "Compiling JSON Schema: "+schemaURL)
+	schema, err := jsonschema.Compile(schemaURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9011, "Fatal error compiling the JSON Schema.", err)
+		return nil
+	}
+	return schema
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate a JSON-line against the compiled schema, if there is one.  The
+// ignored top-level fields are removed from the record beforehand.
+func validateSchema(line []byte, ignoreFields []string) error {
+	if recordSchema == nil {
+		return nil
+	}
+	document, err := schemaDocument(line, ignoreFields)
+	if err != nil {
+		return err
+	}
+	return recordSchema.Validate(document)
+}
+
+// ----------------------------------------------------------------------------
+
+// Decode a JSON-line for schema validation, without the ignored top-level
+// fields.
+func schemaDocument(line []byte, ignoreFields []string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if fields, ok := document.(map[string]interface{}); ok {
+		for _, name := range ignoreFields {
+			delete(fields, name)
+		}
+	}
+	return document, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate a JSON-line against both --schema and --compare-schema.  Returns
+// a description of the difference when the line passes one but not the
+// other, newlyInvalid is true when it's --compare-schema that fails.
+func compareSchemas(line []byte, ignoreFields []string) (drift string, newlyInvalid bool) {
+	document, err := schemaDocument(line, ignoreFields)
+	if err != nil {
+		return "", false
+	}
+	oldErr := recordSchema.Validate(document)
+	newErr := compareSchema.Validate(document)
+	if oldErr == nil && newErr != nil {
+		return "passes --" + Schema + " but fails --" + CompareSchema + ": " + newErr.Error(), true
+	}
+	if oldErr != nil && newErr == nil {
+		return "fails --" + Schema + " but passes --" + CompareSchema, false
+	}
+	return "", false
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Options of the serve subcommand.
+const (
+	defaultPort int = 8080
+	Port            = "port"
+	PortHelp        = "Port the validation service listens on"
+)
+
+// How long in-flight requests get to finish when the service is stopped.
+const shutdownTimeout = 30 * time.Second
+
+// ----------------------------------------------------------------------------
+
+// serveCmd runs validate as an HTTP service.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Validates JSON-lines posted over HTTP.",
+	Long: `
+	Run validate as a service.  Each POST to /validate is validated as JSON-lines
+	and answered with the JSON summary.  The other options are read from the
+	environment and configuration file.
+
+	Usage example:
+
+	validate serve --port 8080
+	curl --data-binary @file.jsonl http://localhost:8080/validate
+	`,
+	PreRun: func(cobraCommand *cobra.Command, args []string) {
+		loadConfigurationFile(cobraCommand)
+		loadOptions(cobraCommand)
+		viper.SetDefault(Port, defaultPort)
+		viper.BindPFlag(Port, cobraCommand.Flags().Lookup(Port))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setLogFormat() {
+			os.Exit(exitCodeOf(statusUsageError))
+		}
+		setLogLevel(cmd)
+		if !openHTTPClient() || !loadSpec() || !loadSchema() || !loadFeatures() || !openEncoding() {
+			os.Exit(exitCodeOf(statusUsageError))
+		}
+		// the summary is the response, per-line messages would only pile up
+		output = newSyncWriter(io.Discard)
+		if err := serve(fmt.Sprintf(":%d", viper.GetInt(Port))); err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9050, "Fatal error running the validation service.", err)
+			fmt.Fprintln(os.Stderr, "Unable to run the validation service:", err)
+			os.Exit(exitCodeOf(statusInputError))
+		}
+	},
+}
+
+// ----------------------------------------------------------------------------
+
+// Serve /validate on address until SIGINT or SIGTERM, then let the requests
+// in flight finish.
+func serve(address string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", handleValidate)
+	server := &http.Server{Addr: address, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	failed := make(chan error, 1)
+	go func() {
+		logger.LogMessage(MessageIdFormat, 43, fmt.Sprintf("Validation service listening on %s.", address))
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+		close(failed)
+	}()
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate the JSON-lines of a POST body and respond with the summary.
+func handleValidate(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", http.MethodPost)
+		http.Error(writer, "POST JSON-lines to validate them", http.StatusMethodNotAllowed)
+		return
+	}
+	result := newSummary("request")
+	body := decodeInput(request.Body)
+	if viper.GetString(InputFormat) == inputFormatJSONArray {
+		validateJSONArray(skipBOM(body), result)
+	} else {
+		scanner, splitter := newLineScanner(body)
+		validateScanner(scanner, splitter, result)
+		if err := scanner.Err(); err != nil {
+			http.Error(writer, "Unable to read the request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	content, err := result.jsonReport()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Write(append(content, '\n'))
+}
+
+// ----------------------------------------------------------------------------
+
+func init() {
+	serveCmd.Flags().Int(Port, defaultPort, PortHelp)
+	RootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/pkg/sftp"
+	"github.com/senzing/senzing-tools/option"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ----------------------------------------------------------------------------
+
+// Stream an sftp://user@host/path file into the validator.  Authentication is
+// by key, from --identity-file and the keys of a running ssh-agent.  The
+// server's host key must be in --known-hosts.  The file type follows
+// --input-file-type or the path's suffix, so gzipped files are decompressed.
+func readSFTPFile(u *url.URL) bool {
+	if len(u.Hostname()) == 0 || len(u.Path) <= 1 {
+		logger.LogMessage(MessageIdFormat, 9042, fmt.Sprintf("Fatal error, an SFTP inputURL needs a host and a path: %s", redactURL(u.String())))
+		output.Println("An SFTP inputURL looks like sftp://user@host/path/file.jsonl")
+		return false
+	}
+	sshConfig, err := newSSHConfig(u)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9043, "Fatal error setting up SSH authentication.", err)
+		output.Println("Unable to set up SSH authentication:", err)
+		return false
+	}
+	address := u.Host
+	if len(u.Port()) == 0 {
+		address = net.JoinHostPort(u.Hostname(), "22")
+	}
+	connection, err := ssh.Dial("tcp", address, sshConfig)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9044, "Fatal error connecting to the SFTP server.", err)
+		output.Println("Unable to connect to", address+":", err)
+		return false
+	}
+	defer connection.Close()
+	client, err := sftp.NewClient(connection)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9044, "Fatal error starting the SFTP session.", err)
+		output.Println("Unable to start the SFTP session:", err)
+		return false
+	}
+	defer client.Close()
+	file, err := client.Open(u.Path)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9045, "Fatal error opening the SFTP file.", err)
+		output.Println("Unable to open", u.Path+":", err)
+		return false
+	}
+	defer file.Close()
+
+	size := int64(-1)
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	body := &countingReader{reader: file}
+	reader := bufio.NewReader(trackProgress(body, size))
+	source := redactURL(u.String())
+	if !validateTypedStream(source, streamType(u.Path, viper.GetString(option.InputFileType), reader), reader) {
+		return false
+	}
+	return checkSize(size, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// The SSH client configuration for an SFTP inputURL.  The user defaults to
+// the current user.
+func newSSHConfig(u *url.URL) (*ssh.ClientConfig, error) {
+	userName := u.User.Username()
+	if len(userName) == 0 {
+		current, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		userName = current.Username
+	}
+	signers, err := sshSigners()
+	if err != nil {
+		return nil, err
+	}
+	knownHosts := viper.GetString(KnownHosts)
+	if len(knownHosts) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("reading --%s: %w", KnownHosts, err)
+	}
+	return &ssh.ClientConfig{
+		User:            userName,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// The keys to authenticate with, from --identity-file then the ssh-agent.
+func sshSigners() ([]ssh.Signer, error) {
+	signers := []ssh.Signer{}
+	if identityFile := viper.GetString(IdentityFile); len(identityFile) > 0 {
+		key, err := os.ReadFile(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --%s: %w", IdentityFile, err)
+		}
+		signers = append(signers, signer)
+	}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); len(socket) > 0 {
+		if connection, err := net.Dial("unix", socket); err == nil {
+			agentSigners, err := agent.NewClient(connection).Signers()
+			if err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("no key to authenticate with, use --" + IdentityFile + " or an ssh-agent")
+	}
+	return signers, nil
+}
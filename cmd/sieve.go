@@ -0,0 +1,75 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// sieveSink writes the valid lines to the --output-valid file and the
+// invalid ones to the --output-invalid file, making validate a filter.
+type sieveSink struct {
+	valid   *splitFile
+	invalid *splitFile
+	// indent the valid records, for --pretty
+	pretty   bool
+	indented bytes.Buffer
+}
+
+// ----------------------------------------------------------------------------
+
+func newSieveSink(validFile string, invalidFile string, pretty bool) (lineSink, error) {
+	sink := &sieveSink{pretty: pretty}
+	var err error
+	if len(validFile) > 0 {
+		if sink.valid, err = createSplitFile(validFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(invalidFile) > 0 {
+		if sink.invalid, err = createSplitFile(invalidFile); err != nil {
+			sink.close()
+			return nil, err
+		}
+	}
+	return sink, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// A line longer than --max-line-bytes isn't kept, so it can't be written.
+// With --pretty a valid record is indented as it is, so its fields keep their
+// order and values.
+func (s *sieveSink) add(line *lineResult) error {
+	out := s.valid
+	if len(line.category) > 0 {
+		out = s.invalid
+	}
+	if out == nil || line.tooLong {
+		return nil
+	}
+	if s.pretty && out == s.valid {
+		s.indented.Reset()
+		if json.Indent(&s.indented, []byte(line.text()), "", "  ") == nil {
+			return out.writeLine(s.indented.String())
+		}
+	}
+	return out.writeLine(line.text())
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *sieveSink) close() error {
+	var firstErr error
+	for _, out := range []*splitFile{s.valid, s.invalid} {
+		if out == nil {
+			continue
+		}
+		if err := out.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// lineSink receives the outcome of every validated line.
+type lineSink interface {
+	add(line *lineResult) error
+	close() error
+}
+
+// The sinks opened for this run.
+var sinks []lineSink
+
+// ----------------------------------------------------------------------------
+
+// Open the sinks requested by the options.
+func openSinks() bool {
+	if sqliteOut := viper.GetString(SqliteOut); len(sqliteOut) > 0 {
+		sink, err := newSQLiteSink(sqliteOut)
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9013, "Fatal error opening the SQLite output.", err)
+			return false
+		}
+		sinks = append(sinks, sink)
+	}
+	if errorFile := viper.GetString(ErrorFile); len(errorFile) > 0 {
+		sink, err := newErrorFileSink(errorFile)
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9038, "Fatal error creating the error file.", err)
+			output.Println("Unable to create the error file:", err)
+			return false
+		}
+		sinks = append(sinks, sink)
+	}
+	if splitOutputDir := viper.GetString(SplitOutputDir); len(splitOutputDir) > 0 {
+		sink, err := newSplitSink(splitOutputDir, viper.GetString(SplitBadFile))
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9023, "Fatal error opening the split output.", err)
+			output.Println("Unable to open the split output:", err)
+			return false
+		}
+		sinks = append(sinks, sink)
+	}
+	if viper.GetBool(Pretty) && len(viper.GetString(OutputValid)) == 0 {
+		logger.LogMessage(MessageIdFormat, 2020, fmt.Sprintf("The --%s option needs --%s.", Pretty, OutputValid))
+		output.Println("--"+Pretty, "needs an --"+OutputValid, "file to write the records to.")
+		return false
+	}
+	if validFile, invalidFile := viper.GetString(OutputValid), viper.GetString(OutputInvalid); len(validFile) > 0 || len(invalidFile) > 0 {
+		sink, err := newSieveSink(validFile, invalidFile, viper.GetBool(Pretty))
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 9058, "Fatal error creating the --output-valid or --output-invalid file.", err)
+			output.Println("Unable to create the valid or invalid lines output:", err)
+			return false
+		}
+		sinks = append(sinks, sink)
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Hand a validated line to every sink.
+func recordLine(line *lineResult) {
+	for _, sink := range sinks {
+		if err := sink.add(line); err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2010, "Error recording a line result.", err)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Flush and close every sink.
+func closeSinks() {
+	for _, sink := range sinks {
+		if err := sink.close(); err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2011, "Error closing a line result output.", err)
+		}
+	}
+	sinks = nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// specVersion is the ruleset of a version of the Generic Entity
+// Specification.
+type specVersion struct {
+	// the version, as in --spec-version
+	name string
+	// whether records must have a RECORD_ID, otherwise one is generated on load
+	requireRecordId bool
+	// the top-level attributes checked by --strict
+	attributes map[string]bool
+}
+
+// The version of the Generic Entity Specification used without --spec-version.
+const latestSpecVersion = "3"
+
+// The supported versions of the Generic Entity Specification.  Version 2
+// didn't require a RECORD_ID, used ENTITY_TYPE rather than RECORD_TYPE and
+// predates the LEI and messaging app attributes.
+var specVersions = map[string]*specVersion{
+	"2": {
+		name:            "2",
+		requireRecordId: false,
+		attributes: withoutAttributes(specAttributes, "RECORD_TYPE", "LEI_NUMBER", "INSTAGRAM", "SIGNAL",
+			"TANGO", "TELEGRAM", "VIBER", "WECHAT", "WHATSAPP", "ZOOMROOM"),
+	},
+	"3": {
+		name:            "3",
+		requireRecordId: true,
+		attributes:      specAttributes,
+	},
+}
+
+// The ruleset records are validated against, set by loadSpec.
+var recordSpec = specVersions[latestSpecVersion]
+
+// The ruleset of --compare-spec-version records are also checked against,
+// nil without it.
+var compareSpec *specVersion
+
+// ----------------------------------------------------------------------------
+
+// Select the rulesets of --spec-version and --compare-spec-version.
+func loadSpec() bool {
+	spec, ok := specVersionOption(SpecVersion)
+	if !ok {
+		return false
+	}
+	recordSpec = spec
+	compareSpec = nil
+	if len(viper.GetString(CompareSpecVersion)) > 0 {
+		if compareSpec, ok = specVersionOption(CompareSpecVersion); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The ruleset of a spec version option, false when the version is unknown.
+func specVersionOption(name string) (*specVersion, bool) {
+	version := strings.TrimPrefix(strings.ToLower(viper.GetString(name)), "v")
+	spec, ok := specVersions[version]
+	if !ok {
+		versions := make([]string, 0, len(specVersions))
+		for known := range specVersions {
+			versions = append(versions, known)
+		}
+		sort.Strings(versions)
+		logger.LogMessage(MessageIdFormat, 9049, fmt.Sprintf("Fatal error, unknown --%s %s.", name, viper.GetString(name)))
+		output.Println("Unknown --"+name, viper.GetString(name)+", use one of", strings.Join(versions, ", "))
+		return nil, false
+	}
+	return spec, true
+}
+
+// ----------------------------------------------------------------------------
+
+// A copy of a set of attributes without the given ones.
+func withoutAttributes(attributes map[string]bool, names ...string) map[string]bool {
+	result := make(map[string]bool, len(attributes))
+	for name := range attributes {
+		result[name] = true
+	}
+	for _, name := range names {
+		delete(result, name)
+	}
+	return result
+}
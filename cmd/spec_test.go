@@ -0,0 +1,40 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// --compare-spec-version counts the records valid under only one of the two
+// spec versions, in each direction, and leaves the bad lines to
+// --spec-version.
+func TestCompareSpecVersion(t *testing.T) {
+	useOptions(t, map[string]interface{}{SpecVersion: "2", CompareSpecVersion: "3", Strict: true, Quiet: true})
+	t.Cleanup(func() { recordSpec, compareSpec = specVersions[latestSpecVersion], nil })
+	if !loadSpec() {
+		t.Fatal("loadSpec failed")
+	}
+	lines := []string{
+		`{"DATA_SOURCE":"TEST","RECORD_ID":"1","NAME_FULL":"Robert Smith"}`,
+		`{"DATA_SOURCE":"TEST","NAME_FULL":"Robert Smith"}`,
+		`{"DATA_SOURCE":"TEST","RECORD_ID":"3","LEI_NUMBER":"5493001KJTIIGC8Y1R12"}`,
+		`{"DATA_SOURCE":"TEST","RECORD_ID":"4","NAME_FILL":"Robert Smith"}`,
+	}
+	result := newSummary("test")
+	checks := newLineChecks(result)
+	for _, text := range lines {
+		line := checks.prepareBytes([]byte(text), "")
+		checks.checkRecord(line)
+		checks.finish(result, line)
+	}
+	if result.ValidOnlyUnderSpec != 1 || result.ValidOnlyUnderCompareSpec != 1 {
+		t.Errorf("valid only under 2 and 3 are %d and %d, want 1 and 1", result.ValidOnlyUnderSpec, result.ValidOnlyUnderCompareSpec)
+	}
+	if result.bad() != 2 {
+		t.Errorf("%d bad line(s), want 2", result.bad())
+	}
+}
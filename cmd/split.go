@@ -0,0 +1,155 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+)
+
+// splitSink writes each valid record to DIR/<DATA_SOURCE>.jsonl and, when a
+// bad file is given, every invalid line to it.
+type splitSink struct {
+	dir   string
+	files map[string]*splitFile
+	bad   *splitFile
+	// the lower cased paths written, so DATA_SOURCE codes that make the same
+	// file name, even on a case-insensitive file system, don't share it
+	paths map[string]bool
+}
+
+// splitFile is a buffered output file.
+type splitFile struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// ----------------------------------------------------------------------------
+
+func newSplitSink(dir string, badFile string) (lineSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	sink := &splitSink{dir: dir, files: map[string]*splitFile{}, paths: map[string]bool{}}
+	if len(badFile) > 0 {
+		bad, err := createSplitFile(badFile)
+		if err != nil {
+			return nil, err
+		}
+		sink.bad = bad
+		sink.paths[splitPathKey(badFile)] = true
+	}
+	return sink, nil
+}
+
+// ----------------------------------------------------------------------------
+
+func createSplitFile(path string) (*splitFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &splitFile{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *splitSink) add(line *lineResult) error {
+	if len(line.category) > 0 {
+		if s.bad == nil {
+			return nil
+		}
+		return s.bad.writeLine(line.text())
+	}
+	dataSource := strings.TrimSpace(*line.id.DataSource)
+	out, ok := s.files[dataSource]
+	if !ok {
+		var err error
+		out, err = createSplitFile(s.pathFor(dataSource))
+		if err != nil {
+			return err
+		}
+		s.files[dataSource] = out
+	}
+	return out.writeLine(line.text())
+}
+
+// ----------------------------------------------------------------------------
+
+// The output file of a DATA_SOURCE not seen before.  When its name is taken
+// by another DATA_SOURCE, like A/B by A_B, a -2, -3, ... suffix is added.
+func (s *splitSink) pathFor(dataSource string) string {
+	name := splitFileName(dataSource)
+	path := filepath.Join(s.dir, name)
+	for n := 2; s.paths[splitPathKey(path)]; n++ {
+		path = filepath.Join(s.dir, fmt.Sprintf("%s-%d.jsonl", strings.TrimSuffix(name, ".jsonl"), n))
+	}
+	if filepath.Base(path) != name {
+		logger.LogMessage(MessageIdFormat, 63, fmt.Sprintf("DATA_SOURCE %s is written to %s, %s is taken by another DATA_SOURCE.", dataSource, path, name))
+	}
+	s.paths[splitPathKey(path)] = true
+	return path
+}
+
+// ----------------------------------------------------------------------------
+
+// The key of an output path in splitSink.paths.
+func splitPathKey(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	return strings.ToLower(path)
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *splitSink) close() error {
+	var firstErr error
+	for _, out := range s.files {
+		if err := out.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if s.bad != nil {
+		if err := s.bad.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ----------------------------------------------------------------------------
+
+// A file system safe name for the output file of a DATA_SOURCE.
+func splitFileName(dataSource string) string {
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(dataSource, "_"), "_.")
+	if len(name) == 0 {
+		name = "_"
+	}
+	return name + ".jsonl"
+}
+
+// ----------------------------------------------------------------------------
+
+func (f *splitFile) writeLine(line string) error {
+	if _, err := f.writer.WriteString(line); err != nil {
+		return err
+	}
+	return f.writer.WriteByte('\n')
+}
+
+// ----------------------------------------------------------------------------
+
+func (f *splitFile) close() error {
+	if err := f.writer.Flush(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roncewind/validate/jsonl"
+)
+
+// ----------------------------------------------------------------------------
+
+// DATA_SOURCE codes that make the same file name each get a file of their
+// own, none truncating another.
+func TestSplitFileNameCollisions(t *testing.T) {
+	useOptions(t, nil)
+	dir := t.TempDir()
+	sink, err := newSplitSink(dir, filepath.Join(dir, "bad.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataSources := []string{"A/B", "A_B", "X.", "X", "a_b", "BAD", "A/B"}
+	for i, dataSource := range dataSources {
+		dataSource := dataSource
+		text := `{"DATA_SOURCE":"` + dataSource + `","RECORD_ID":"` + string(rune('1'+i)) + `"}`
+		line := &lineResult{id: jsonl.Identity{DataSource: &dataSource}, raw: []byte(text), line: text}
+		if err := sink.add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{
+		"A_B.jsonl":   2,
+		"A_B-2.jsonl": 1,
+		"X.jsonl":     1,
+		"X-2.jsonl":   1,
+		"a_b-3.jsonl": 1,
+		"BAD-2.jsonl": 1,
+		"bad.jsonl":   0,
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("%d files written, want %d", len(entries), len(want))
+	}
+	for name, lines := range want {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := strings.Count(string(content), "\n"); got != lines {
+			t.Errorf("%s has %d lines, want %d", name, got, lines)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+//go:build sqlite
+
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	sqliteBatchSize     = 10000
+	sqliteExcerptLength = 200
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS results (
+	source      TEXT,
+	line_no     INTEGER,
+	valid       INTEGER,
+	category    TEXT,
+	record_id   TEXT,
+	data_source TEXT,
+	excerpt     TEXT
+)`
+
+const sqliteInsert = `INSERT INTO results (source, line_no, valid, category, record_id, data_source, excerpt) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+// ----------------------------------------------------------------------------
+
+// sqliteSink writes one row per line into a SQLite database, committing in
+// batches for speed.
+type sqliteSink struct {
+	db        *sql.DB
+	tx        *sql.Tx
+	statement *sql.Stmt
+	pending   int
+}
+
+// ----------------------------------------------------------------------------
+
+func newSQLiteSink(path string) (lineSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	sink := &sqliteSink{db: db}
+	if err := sink.begin(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *sqliteSink) begin() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	statement, err := tx.Prepare(sqliteInsert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	s.tx = tx
+	s.statement = statement
+	s.pending = 0
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *sqliteSink) commit() error {
+	s.statement.Close()
+	return s.tx.Commit()
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *sqliteSink) add(line *lineResult) error {
+	var recordId, dataSource interface{}
+	if line.id.RecordId != nil {
+		recordId = *line.id.RecordId
+	}
+	if line.id.DataSource != nil {
+		dataSource = *line.id.DataSource
+	}
+	excerpt := line.text()
+	if len(excerpt) > sqliteExcerptLength {
+		excerpt = excerpt[:sqliteExcerptLength]
+	}
+	_, err := s.statement.Exec(line.source, line.number, len(line.category) == 0, line.category, recordId, dataSource, excerpt)
+	if err != nil {
+		return err
+	}
+	s.pending++
+	if s.pending >= sqliteBatchSize {
+		if err := s.commit(); err != nil {
+			return err
+		}
+		return s.begin()
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *sqliteSink) close() error {
+	err := s.commit()
+	if closeErr := s.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !sqlite
+
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import "errors"
+
+// ----------------------------------------------------------------------------
+
+// SQLite support is only linked in when built with "-tags sqlite".
+func newSQLiteSink(path string) (lineSink, error) {
+	return nil, errors.New("this build of validate does not include SQLite support, rebuild with -tags sqlite")
+}
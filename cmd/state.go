@@ -0,0 +1,154 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Read an http(s) input unless --state-file records that its current ETag
+// already validated cleanly, see readUnlessStored.  Inputs re-fetched by
+// --watch-remote or read with another --http-method are always read.
+func readUnlessUnchanged(u *url.URL, read func() bool) bool {
+	if viper.GetBool(WatchRemote) || strings.ToUpper(viper.GetString(HttpMethod)) != http.MethodGet {
+		return read()
+	}
+	return readUnlessStored(u, func() string { return resourceETag(u.String()) }, read)
+}
+
+// ----------------------------------------------------------------------------
+
+// Read an input unless --state-file records that its current ETag, given
+// by etag, already validated cleanly.  After a clean validation of the whole
+// input, whatever the outcome of the inputs before it, the ETag is recorded
+// so the next run can skip the unchanged input.  The http(s), S3, GCS and
+// Azure inputs are tracked.
+func readUnlessStored(u *url.URL, etag func() string, read func() bool) bool {
+	stateFile := viper.GetString(StateFile)
+	if len(stateFile) == 0 {
+		return read()
+	}
+	state, err := loadState(stateFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9029, "Fatal error reading the state file.", err)
+		output.Println("Unable to read the state file:", err)
+		return false
+	}
+	key := stateKey(u)
+	current := etag()
+	if len(current) > 0 && state[key] == current {
+		logger.LogMessage(MessageIdFormat, 27, "Skipping the unchanged inputURL.")
+		output.Println("Skipped, unchanged:", key)
+		return true
+	}
+	badBefore := badLines
+	ok, status := readWithStatus(read)
+	if !ok {
+		return false
+	}
+	if len(current) > 0 && badLines == badBefore && status == statusClean && !stoppedEarly {
+		state[key] = current
+		if err := saveState(stateFile, state); err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2014, "Error writing the state file.", err)
+			output.Println("Unable to write the state file:", err)
+		}
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// The ETag the server currently gives an input, empty when it gives none.
+func resourceETag(resourceURL string) string {
+	request, err := http.NewRequestWithContext(runContext, http.MethodHead, resourceURL, nil)
+	if err != nil || addHeaders(request) != nil {
+		return ""
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return ""
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return ""
+	}
+	return response.Header.Get("ETag")
+}
+
+// ----------------------------------------------------------------------------
+
+// The state file key of an input: its URL without credentials, keeping only
+// the query parameters that name a file, so signed URLs keep the same key.
+func stateKey(u *url.URL) string {
+	key := *u
+	key.User = nil
+	query := url.Values{}
+	for _, parameter := range fileNameParameters {
+		if value := u.Query().Get(parameter); len(value) > 0 {
+			query.Set(parameter, value)
+		}
+	}
+	key.RawQuery = query.Encode()
+	key.Fragment = ""
+	return key.String()
+}
+
+// ----------------------------------------------------------------------------
+
+// Load the {url: etag} state, empty when the file doesn't exist yet.
+func loadState(stateFile string) (map[string]string, error) {
+	state := map[string]string{}
+	content, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, json.Unmarshal(content, &state)
+}
+
+// ----------------------------------------------------------------------------
+
+// Write the state through a temporary file so an interrupted run can't leave
+// it truncated.
+func saveState(stateFile string, state map[string]string) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return replaceFile(stateFile, append(content, '\n'))
+}
+
+// ----------------------------------------------------------------------------
+
+// Replace a file's content through a temporary file, renamed over it, so it
+// is never left half written.
+func replaceFile(path string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// The ETag of an input is recorded by its own outcome, not by that of the
+// inputs read before it.
+func TestStateFileInputOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL + "/records.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := runStatus
+	t.Cleanup(func() { runStatus = saved })
+
+	tests := []struct {
+		name   string
+		before exitStatus
+		raised exitStatus
+		stored bool
+	}{
+		{"clean", statusClean, statusClean, true},
+		{"clean after a failed input", statusInputError, statusClean, true},
+		{"failed", statusClean, statusInputError, false},
+		{"timed out", statusClean, statusTimedOut, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stateFile := t.TempDir() + "/state.json"
+			useOptions(t, map[string]interface{}{StateFile: stateFile})
+			captureOutput(t)
+			runStatus, stoppedEarly = test.before, false
+			read := readUnlessUnchanged(u, func() bool {
+				raiseStatus(test.raised)
+				return true
+			})
+			if !read {
+				t.Fatal("the input wasn't read")
+			}
+			if want := max(test.before, test.raised); runStatus != want {
+				t.Errorf("the run status is %d, want %d", runStatus, want)
+			}
+			state, err := loadState(stateFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored := state[stateKey(u)] == `"v1"`; stored != test.stored {
+				t.Errorf("the ETag is stored %v, want %v: %v", stored, test.stored, state)
+			}
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// An object store input whose ETag validated cleanly is skipped the next
+// run, and read again once its ETag changes.
+func TestStateFileObjectETag(t *testing.T) {
+	u, err := url.Parse("s3://bucket/records.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useOptions(t, map[string]interface{}{StateFile: t.TempDir() + "/state.json"})
+	captureOutput(t)
+	saved := runStatus
+	t.Cleanup(func() { runStatus = saved })
+	runStatus, stoppedEarly = statusClean, false
+
+	for _, test := range []struct {
+		etag string
+		read bool
+	}{
+		{`"v1"`, true},
+		{`"v1"`, false},
+		{`"v2"`, true},
+	} {
+		read := false
+		if !readUnlessStored(u, func() string { return test.etag }, func() bool {
+			read = true
+			return true
+		}) {
+			t.Fatal("the input failed")
+		}
+		if read != test.read {
+			t.Errorf("with ETag %s the input is read %v, want %v", test.etag, read, test.read)
+		}
+	}
+}
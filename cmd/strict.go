@@ -0,0 +1,80 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"sort"
+	"strings"
+)
+
+// The top-level attributes of the latest Generic Entity Specification.
+var specAttributes = map[string]bool{
+	// identity
+	"DATA_SOURCE": true, "RECORD_ID": true, "RECORD_TYPE": true, "ENTITY_TYPE": true, "DSRC_ACTION": true,
+	// names
+	"NAME_TYPE": true, "NAME_FULL": true, "NAME_ORG": true, "NAME_LAST": true, "NAME_FIRST": true,
+	"NAME_MIDDLE": true, "NAME_PREFIX": true, "NAME_SUFFIX": true,
+	// characteristics
+	"GENDER": true, "DATE_OF_BIRTH": true, "DATE_OF_DEATH": true, "PLACE_OF_BIRTH": true,
+	"NATIONALITY": true, "CITIZENSHIP": true, "REGISTRATION_DATE": true, "REGISTRATION_COUNTRY": true,
+	// addresses
+	"ADDR_TYPE": true, "ADDR_FULL": true, "ADDR_LINE1": true, "ADDR_LINE2": true, "ADDR_LINE3": true,
+	"ADDR_LINE4": true, "ADDR_LINE5": true, "ADDR_LINE6": true, "ADDR_CITY": true, "ADDR_STATE": true,
+	"ADDR_POSTAL_CODE": true, "ADDR_COUNTRY": true, "ADDR_FROM_DATE": true, "ADDR_THRU_DATE": true,
+	// phones
+	"PHONE_TYPE": true, "PHONE_NUMBER": true, "PHONE_FROM_DATE": true, "PHONE_THRU_DATE": true,
+	// identifiers
+	"PASSPORT_NUMBER": true, "PASSPORT_COUNTRY": true, "DRIVERS_LICENSE_NUMBER": true, "DRIVERS_LICENSE_STATE": true,
+	"SSN_NUMBER": true, "SSN_LAST4": true, "NATIONAL_ID_NUMBER": true, "NATIONAL_ID_TYPE": true,
+	"NATIONAL_ID_COUNTRY": true, "TAX_ID_NUMBER": true, "TAX_ID_TYPE": true, "TAX_ID_COUNTRY": true,
+	"OTHER_ID_NUMBER": true, "OTHER_ID_TYPE": true, "OTHER_ID_COUNTRY": true, "TRUSTED_ID_NUMBER": true,
+	"TRUSTED_ID_TYPE": true, "ACCOUNT_NUMBER": true, "ACCOUNT_DOMAIN": true, "DUNS_NUMBER": true,
+	"NPI_NUMBER": true, "LEI_NUMBER": true,
+	// electronic addresses
+	"EMAIL_ADDRESS": true, "WEBSITE_ADDRESS": true, "LINKEDIN": true, "FACEBOOK": true, "TWITTER": true,
+	"SKYPE": true, "ZOOMROOM": true, "INSTAGRAM": true, "WHATSAPP": true, "SIGNAL": true, "TELEGRAM": true,
+	"TANGO": true, "VIBER": true, "WECHAT": true,
+	// groups
+	"EMPLOYER_NAME": true, "GROUP_ASSOCIATION_TYPE": true, "GROUP_ASSOCIATION_ORG_NAME": true,
+	"GROUP_ASSN_ID_TYPE": true, "GROUP_ASSN_ID_NUMBER": true,
+	// relationships
+	"REL_ANCHOR_DOMAIN": true, "REL_ANCHOR_KEY": true, "REL_POINTER_DOMAIN": true, "REL_POINTER_KEY": true,
+	"REL_POINTER_ROLE": true,
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether a top-level key is part of this version of the Generic Entity
+// Specification.  Keys are matched exactly, so a typo like RECORD_iD is
+// unknown.  An attribute may carry a usage prefix, as in HOME_ADDR_LINE1, and
+// a list, like "NAMES": [{...}], may have any name.
+func (spec *specVersion) isSpecKey(key string, value interface{}) bool {
+	if spec.attributes[key] {
+		return true
+	}
+	if _, isList := value.([]interface{}); isList {
+		return true
+	}
+	for _, rest, found := strings.Cut(key, "_"); found; _, rest, found = strings.Cut(rest, "_") {
+		if spec.attributes[rest] {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+
+// The top-level keys of a record that aren't in this version of the Generic
+// Entity Specification, in sorted order.
+func (spec *specVersion) unknownKeys(fields map[string]interface{}) []string {
+	unknown := []string{}
+	for key, value := range fields {
+		if !spec.isSpecKey(key, value) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
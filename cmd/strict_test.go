@@ -0,0 +1,34 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// --ignore-fields keeps benign metadata from tripping --strict, other keys
+// not in the specification still do.
+func TestStrictIgnoreFields(t *testing.T) {
+	tests := []struct {
+		line     string
+		ignore   []string
+		category string
+	}{
+		{`{"DATA_SOURCE":"TEST","RECORD_ID":"1","_ingest_id":"a1"}`, nil, categoryUnknownKeys},
+		{`{"DATA_SOURCE":"TEST","RECORD_ID":"1","_ingest_id":"a1"}`, []string{"_ingest_id"}, ""},
+		{`{"DATA_SOURCE":"TEST","RECORD_ID":"1","_ingest_id":"a1","_other":1}`, []string{"_ingest_id"}, categoryUnknownKeys},
+		{`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}`, []string{"_ingest_id"}, ""},
+	}
+	for _, test := range tests {
+		useOptions(t, map[string]interface{}{Strict: true, IgnoreFields: test.ignore})
+		checks := newLineChecks(newSummary("test"))
+		line := checks.prepareBytes([]byte(test.line), "")
+		checks.validate(line)
+		if line.category != test.category {
+			t.Errorf("%s with --ignore-fields %v is %q, want %q: %s", test.line, test.ignore, line.category, test.category, line.message)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/roncewind/validate/jsonl"
+)
+
+// Lines longer than this aren't tried with fixes, to keep --suggest-fixes cheap.
+const maxSuggestLineLength = 64 * 1024
+
+// fix is a safe normalization of a line, it returns false when it doesn't
+// apply to the line.
+type fix struct {
+	description string
+	apply       func(line string) (string, bool)
+}
+
+var fixes = []fix{
+	{"removing the byte order mark", removeByteOrderMark},
+	{"removing the trailing comma", removeTrailingComma},
+	{"trimming whitespace from field names and values", trimFields},
+	{"quoting a numeric RECORD_ID or DATA_SOURCE", quoteIdentifiers},
+}
+
+// ----------------------------------------------------------------------------
+
+// Try each fix on a line that failed the base checks and return the
+// descriptions of the ones that would have made it pass.
+func suggestFixes(line string) []string {
+	if len(line) > maxSuggestLineLength {
+		return nil
+	}
+	var suggestions []string
+	for _, candidate := range fixes {
+		if fixed, applies := candidate.apply(line); applies && passesBaseChecks(fixed) {
+			suggestions = append(suggestions, candidate.description)
+		}
+	}
+	return suggestions
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether a line passes record.Validate and has no empty required field.
+func passesBaseChecks(line string) bool {
+	valid, id, _ := jsonl.ValidateRecord([]byte(line), recordSpec.requireRecordId)
+	return valid && len(id.EmptyField(recordSpec.requireRecordId)) == 0
+}
+
+// ----------------------------------------------------------------------------
+
+func removeByteOrderMark(line string) (string, bool) {
+	return strings.TrimPrefix(line, "\uFEFF"), strings.HasPrefix(line, "\uFEFF")
+}
+
+// ----------------------------------------------------------------------------
+
+func removeTrailingComma(line string) (string, bool) {
+	return strings.TrimRight(line, ", \t"), strings.HasSuffix(line, ",")
+}
+
+// ----------------------------------------------------------------------------
+
+func trimFields(line string) (string, bool) {
+	return rewriteFields(line, func(name string, value interface{}) (string, interface{}) {
+		if text, ok := value.(string); ok {
+			value = strings.TrimSpace(text)
+		}
+		return strings.TrimSpace(name), value
+	})
+}
+
+// ----------------------------------------------------------------------------
+
+func quoteIdentifiers(line string) (string, bool) {
+	return rewriteFields(line, func(name string, value interface{}) (string, interface{}) {
+		if number, ok := value.(json.Number); ok && (name == "RECORD_ID" || name == "DATA_SOURCE") {
+			value = number.String()
+		}
+		return name, value
+	})
+}
+
+// ----------------------------------------------------------------------------
+
+// Rewrite every top-level field of a line.  Returns false when the line isn't
+// a JSON object or no field changed.
+func rewriteFields(line string, rewrite func(name string, value interface{}) (string, interface{})) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if decoder.Decode(&fields) != nil {
+		return line, false
+	}
+	changed := false
+	rewritten := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		newName, newValue := rewrite(name, value)
+		if newName != name || !sameValue(newValue, value) {
+			changed = true
+		}
+		rewritten[newName] = newValue
+	}
+	if !changed {
+		return line, false
+	}
+	content, err := json.Marshal(rewritten)
+	if err != nil {
+		return line, false
+	}
+	return string(content), true
+}
+
+// ----------------------------------------------------------------------------
+
+func sameValue(a interface{}, b interface{}) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return bytes.Equal(aJSON, bJSON)
+}
@@ -0,0 +1,654 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/roncewind/validate/jsonl"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// summary accumulates the outcome of validating one or more streams of lines.
+type summary struct {
+	Source string `json:"source"`
+	// the number of inputs of the aggregate --report-dir report
+	Inputs int `json:"inputs,omitempty"`
+	jsonl.Counts
+	MissingFields map[string]int    `json:"missingFields,omitempty"`
+	DataSources   map[string]int    `json:"dataSources,omitempty"`
+	Histogram     []histogramBucket `json:"histogram,omitempty"`
+	Bad           int               `json:"bad"`
+	NewlyInvalid  int               `json:"newlyInvalid,omitempty"`
+	NewlyValid    int               `json:"newlyValid,omitempty"`
+	// the records valid under only one of --spec-version and
+	// --compare-spec-version
+	ValidOnlyUnderSpec        int              `json:"validOnlyUnderSpecVersion,omitempty"`
+	ValidOnlyUnderCompareSpec int              `json:"validOnlyUnderCompareSpecVersion,omitempty"`
+	Valid                     bool             `json:"valid"`
+	StoppedEarly              bool             `json:"stoppedEarly,omitempty"`
+	StartLine                 int              `json:"startLine,omitempty"`
+	EndLine                   int              `json:"endLine,omitempty"`
+	Incomplete                bool             `json:"incomplete,omitempty"`
+	TimedOut                  bool             `json:"timedOut,omitempty"`
+	Examples                  map[string][]int `json:"examples,omitempty"`
+	Sampled                   int              `json:"sampled,omitempty"`
+	SampleRate                float64          `json:"sampleRate,omitempty"`
+	SampleSize                int              `json:"sampleSize,omitempty"`
+	EstimatedBad              int              `json:"estimatedBad,omitempty"`
+	EstimatedBadRate          float64          `json:"estimatedBadRate,omitempty"`
+	Seed                      int64            `json:"seed,omitempty"`
+	Run                       *runMetadata     `json:"run,omitempty"`
+	started                   time.Time
+	lines                     int // read so far, including blank lines
+	skipLines                 int // already validated before a --resume
+	examplesPerCategory       int
+	dataSourceStats           bool
+	histogramBuckets          int
+	groups                    *jsonl.GroupTracker
+	duplicates                *jsonl.DuplicateTracker
+}
+
+// runMetadata describes the validation run that produced a summary.
+type runMetadata struct {
+	Version   string    `json:"version"`
+	Iteration string    `json:"iteration"`
+	Hostname  string    `json:"hostname"`
+	Arguments []string  `json:"arguments"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Duration  string    `json:"duration"`
+}
+
+// Values of --report-format.
+const (
+	reportFormatCSV  = "csv"
+	reportFormatJSON = "json"
+	reportFormatText = "text"
+)
+
+// The lines and bad lines of every summary reported during this run.
+var (
+	totalLines int
+	badLines   int
+)
+
+// Whether the most recently reported summary stopped early, leaving the rest
+// of its input unread.
+var stoppedEarly bool
+
+// The aggregate of the summaries reported during this run, nil until the
+// first one is.
+var runSummary *summary
+
+// The file name of the aggregate --report-dir report.
+const aggregateReportName = "aggregate.json"
+
+// The --report-dir report file names by source, and the sources by lower
+// cased file name, so two sources never share a report file.
+var (
+	reportNames   = map[string]string{}
+	reportSources = map[string]string{aggregateReportName: ""}
+)
+
+// Whether --fail-fast stopped the run at a bad line.  The remaining inputs of
+// a directory, glob or zip archive are then skipped.
+var failedFast bool
+
+// The columns of --report-format csv, a column for each category of the JSON
+// summary after the totals, so the categories add up to bad.
+var csvColumns = append([]string{"source", "total", "valid", "bad"}, jsonl.Categories...)
+
+// Whether the CSV header row has been printed.
+var csvHeaderPrinted bool
+
+// countReport is the JSON summary of --count-only.
+type countReport struct {
+	Source     string `json:"source"`
+	TotalLines int    `json:"totalLines"`
+	ValidLines int    `json:"validLines"`
+	Bad        int    `json:"bad"`
+}
+
+// Characters that aren't safe to use in a report file name.
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ----------------------------------------------------------------------------
+
+// Create an empty summary for the given source, starting the run clock.
+func newSummary(source string) *summary {
+	result := &summary{
+		Source:              source,
+		started:             time.Now(),
+		examplesPerCategory: viper.GetInt(ExamplesPerCategory),
+		dataSourceStats:     viper.GetBool(DataSourceStats),
+		histogramBuckets:    viper.GetInt(HistogramBuckets),
+		StartLine:           viper.GetInt(StartLine),
+		EndLine:             viper.GetInt(EndLine),
+	}
+	if lineSampler != nil {
+		result.SampleRate = lineSampler.rate
+		result.Seed = lineSampler.seed
+	}
+	return result
+}
+
+// ----------------------------------------------------------------------------
+
+// The number of lines that failed validation for any reason.
+func (s *summary) bad() int {
+	return s.BadLines()
+}
+
+// ----------------------------------------------------------------------------
+
+// The number of non-blank lines validated, those left out of a --sample-rate
+// sample aside.
+func (s *summary) validated() int {
+	if s.SampleRate > 0 {
+		return s.Sampled
+	}
+	return s.TotalLines
+}
+
+// ----------------------------------------------------------------------------
+
+// Extrapolate the bad lines of a --sample-rate sample to every non-blank
+// line, with the 95% margin of error of the bad fraction.
+func (s *summary) estimate() {
+	if s.Sampled == 0 {
+		output.Println("  No line was sampled, so the bad lines can't be estimated.")
+		return
+	}
+	fraction := float64(s.bad()) / float64(s.Sampled)
+	margin := 1.96 * math.Sqrt(fraction*(1-fraction)/float64(s.Sampled))
+	s.EstimatedBadRate = fraction
+	s.EstimatedBad = int(math.Round(fraction * float64(s.TotalLines)))
+	output.Printf("  This is an estimate: about %d of the %d line(s) are bad, %.2f%% ± %.2f%%.\n", s.EstimatedBad, s.TotalLines, 100*fraction, 100*margin)
+}
+
+// ----------------------------------------------------------------------------
+
+// Count the --require-field requirements a line didn't meet.
+func (s *summary) countMissing(requirements []string) {
+	if len(requirements) == 0 {
+		return
+	}
+	if s.MissingFields == nil {
+		s.MissingFields = map[string]int{}
+	}
+	for _, requirement := range requirements {
+		s.MissingFields[requirement]++
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Print how many lines missed each --require-field requirement.
+func (s *summary) printMissingFields() {
+	requirements := make([]string, 0, len(s.MissingFields))
+	for requirement := range s.MissingFields {
+		requirements = append(requirements, requirement)
+	}
+	sort.Strings(requirements)
+	for _, requirement := range requirements {
+		output.Printf("  %d line(s) were missing %s.\n", s.MissingFields[requirement], requirement)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a record under its DATA_SOURCE for --data-source-stats.  Records
+// without one aren't counted.
+func (s *summary) countDataSource(id jsonl.Identity) {
+	if !s.dataSourceStats || id.DataSource == nil || len(*id.DataSource) == 0 {
+		return
+	}
+	if s.DataSources == nil {
+		s.DataSources = map[string]int{}
+	}
+	s.DataSources[*id.DataSource]++
+}
+
+// ----------------------------------------------------------------------------
+
+// Print the records of each DATA_SOURCE, the largest count first.
+func (s *summary) printDataSources() {
+	if len(s.DataSources) == 0 {
+		return
+	}
+	codes := make([]string, 0, len(s.DataSources))
+	for code := range s.DataSources {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if s.DataSources[codes[i]] != s.DataSources[codes[j]] {
+			return s.DataSources[codes[i]] > s.DataSources[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	output.Println("  Records by DATA_SOURCE:")
+	for _, code := range codes {
+		output.Printf("    %8d  %s\n", s.DataSources[code], code)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Count an invalid line in its category, keeping the first line numbers of
+// each category as examples.
+func (s *summary) add(category string, lineNumber int) {
+	if s.examplesPerCategory > 0 {
+		if s.Examples == nil {
+			s.Examples = map[string][]int{}
+		}
+		if len(s.Examples[category]) < s.examplesPerCategory {
+			s.Examples[category] = append(s.Examples[category], lineNumber)
+		}
+	}
+	s.countInHistogram(category, lineNumber)
+	s.Counts.Add(category)
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a line whose outcome differs between --schema and --compare-schema.
+// These lines aren't counted as bad.
+func (s *summary) addDrift(newlyInvalid bool) {
+	if newlyInvalid {
+		s.NewlyInvalid++
+	} else {
+		s.NewlyValid++
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a record valid under only one of --spec-version and
+// --compare-spec-version.  These lines are counted under --spec-version.
+func (s *summary) addSpecDrift(validOnlySpec bool) {
+	if validOnlySpec {
+		s.ValidOnlyUnderSpec++
+	} else {
+		s.ValidOnlyUnderCompareSpec++
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Log the per-category counts and print the final tally.  When --report-dir
+// is given, the summary is also written there as JSON.
+func (s *summary) report() {
+	if viper.GetBool(CountOnly) {
+		s.reportCounts()
+		return
+	}
+	if s.NoRecordId > 0 {
+		logger.LogMessage(MessageIdFormat, 5, fmt.Sprintf("%d line(s) had no RECORD_ID field.", s.NoRecordId))
+	}
+	if s.NoDataSource > 0 {
+		logger.LogMessage(MessageIdFormat, 6, fmt.Sprintf("%d line(s) had no DATA_SOURCE field.", s.NoDataSource))
+	}
+	if s.EmptyRecordId > 0 {
+		logger.LogMessage(MessageIdFormat, 11, fmt.Sprintf("%d line(s) had an empty RECORD_ID field.", s.EmptyRecordId))
+	}
+	if s.EmptyDataSource > 0 {
+		logger.LogMessage(MessageIdFormat, 12, fmt.Sprintf("%d line(s) had an empty DATA_SOURCE field.", s.EmptyDataSource))
+	}
+	if s.Malformed > 0 {
+		logger.LogMessage(MessageIdFormat, 7, fmt.Sprintf("%d line(s) are not well formed JSON-lines.", s.Malformed))
+	}
+	if s.BadRecord > 0 {
+		logger.LogMessage(MessageIdFormat, 8, fmt.Sprintf("%d line(s) did not validate for an unknown reason.", s.BadRecord))
+	}
+	if s.NotNormalized > 0 {
+		logger.LogMessage(MessageIdFormat, 10, fmt.Sprintf("%d line(s) had text that is not NFC-normalized.", s.NotNormalized))
+	}
+	if s.SchemaInvalid > 0 {
+		logger.LogMessage(MessageIdFormat, 15, fmt.Sprintf("%d line(s) did not conform to the JSON Schema.", s.SchemaInvalid))
+	}
+	if s.UngroupedDataSource > 0 {
+		logger.LogMessage(MessageIdFormat, 16, fmt.Sprintf("%d line(s) broke the grouping of records by DATA_SOURCE.", s.UngroupedDataSource))
+	}
+	if s.UnknownFeature > 0 {
+		logger.LogMessage(MessageIdFormat, 26, fmt.Sprintf("%d line(s) used feature names missing from the features config.", s.UnknownFeature))
+	}
+	if s.UnknownKeys > 0 {
+		logger.LogMessage(MessageIdFormat, 37, fmt.Sprintf("%d line(s) had top-level keys not in the Generic Entity Specification.", s.UnknownKeys))
+	}
+	if s.DisallowedDataSource > 0 {
+		logger.LogMessage(MessageIdFormat, 38, fmt.Sprintf("%d line(s) had a DATA_SOURCE that is not allowed.", s.DisallowedDataSource))
+	}
+	if s.DuplicateRecordId > 0 {
+		logger.LogMessage(MessageIdFormat, 39, fmt.Sprintf("%d line(s) reused a RECORD_ID already seen in their DATA_SOURCE.", s.DuplicateRecordId))
+	}
+	if s.LineTooLong > 0 {
+		logger.LogMessage(MessageIdFormat, 40, fmt.Sprintf("%d line(s) were longer than --%s and not validated.", s.LineTooLong, MaxLineBytes))
+	}
+	if s.MissingRequiredField > 0 {
+		logger.LogMessage(MessageIdFormat, 49, fmt.Sprintf("%d line(s) were missing a --%s field.", s.MissingRequiredField, RequireField))
+	}
+	s.Run = newRunMetadata(s.started)
+	logger.LogMessage(MessageIdFormat, 13, fmt.Sprintf("validate %s-%s on %s took %s.", s.Run.Version, s.Run.Iteration, s.Run.Hostname, s.Run.Duration))
+	logger.LogMessage(MessageIdFormat, 9, fmt.Sprintf("Validated %d lines, %d were bad.", s.TotalLines, s.bad()))
+	output.Println(passFail(s.bad(), fmt.Sprintf("Validated %d lines, %d were bad.", s.TotalLines, s.bad())))
+	if s.BlankLines > 0 {
+		output.Printf("  %d blank line(s) were skipped.\n", s.BlankLines)
+	}
+	if s.StoppedEarly && failedFast {
+		output.Printf("  Stopped at the first bad line, --%s was given, the rest of the input was not validated.\n", FailFast)
+	} else if s.TimedOut {
+		output.Printf("  Stopped when the --%s passed, the rest of the input was not validated.\n", Timeout)
+	} else if s.StoppedEarly && s.SampleSize > 0 {
+		output.Printf("  This is a sample, only the first %d non-blank line(s) were validated, as --%s was given.\n", s.SampleSize, Sample)
+	} else if s.StoppedEarly && !s.endOfRange() {
+		output.Printf("  Stopped early, --%s was reached, the rest of the input was not validated.\n", MaxErrors)
+	}
+	if s.StartLine > 1 || s.EndLine > 0 {
+		output.Printf("  Only lines %d to %d were validated, the --%s and --%s range.\n", max(s.StartLine, 1), s.lines, StartLine, EndLine)
+	}
+	if s.Incomplete {
+		output.Println("  The input ended unexpectedly, only the lines read before the error were validated.")
+	}
+	s.addToRun()
+	if s.SampleRate > 0 {
+		output.Printf("  %d non-blank line(s) were sampled at rate %g with seed %d.\n", s.Sampled, s.SampleRate, s.Seed)
+		s.estimate()
+	}
+	s.printExamples()
+	s.printMissingFields()
+	s.printDataSources()
+	s.printHistogram()
+	if compareSchema != nil {
+		output.Printf("  %d line(s) pass --%s but fail --%s, %d line(s) fail --%s but pass --%s.\n", s.NewlyInvalid, Schema, CompareSchema, s.NewlyValid, Schema, CompareSchema)
+	}
+	if compareSpec != nil {
+		output.Printf("  %d record(s) valid under --%s %s only, %d record(s) valid under --%s %s only.\n", s.ValidOnlyUnderSpec, SpecVersion, recordSpec.name, s.ValidOnlyUnderCompareSpec, CompareSpecVersion, compareSpec.name)
+	}
+
+	if viper.GetString(ReportFormat) == reportFormatJSON {
+		s.printReport()
+	} else if viper.GetString(ReportFormat) == reportFormatCSV {
+		s.printCSVRow()
+	}
+	if reportDir := viper.GetString(ReportDir); len(reportDir) > 0 {
+		s.writeReport(reportDir)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The summary as indented JSON, with any credentials hidden from the source.
+func (s *summary) jsonReport() ([]byte, error) {
+	report := *s
+	report.Source = redactURL(s.Source)
+	report.Bad = s.bad()
+	report.Valid = report.Bad == 0 && !s.Incomplete && !s.TimedOut
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// ----------------------------------------------------------------------------
+
+// Report just the counts for --count-only.  The --report-dir report is
+// written in full.
+func (s *summary) reportCounts() {
+	s.Run = newRunMetadata(s.started)
+	valid := s.validated() - s.bad()
+	logger.LogMessage(MessageIdFormat, 9, fmt.Sprintf("Validated %d lines, %d were bad.", s.TotalLines, s.bad()))
+	output.Println(passFail(s.bad(), fmt.Sprintf("%d lines, %d valid, %d bad.", s.TotalLines, valid, s.bad())))
+	s.addToRun()
+	if viper.GetString(ReportFormat) == reportFormatJSON {
+		content, err := json.MarshalIndent(countReport{Source: redactURL(s.Source), TotalLines: s.TotalLines, ValidLines: valid, Bad: s.bad()}, "", "  ")
+		if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2007, "Error building the JSON report.", err)
+			return
+		}
+		if !s.silenced() {
+			os.Stdout.Write(append(content, '\n'))
+		}
+	} else if viper.GetString(ReportFormat) == reportFormatCSV {
+		s.printCSVRow()
+	}
+	if reportDir := viper.GetString(ReportDir); len(reportDir) > 0 {
+		s.writeReport(reportDir)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Add the counts of a reported summary to the totals of the run.
+func (s *summary) addToRun() {
+	totalLines += s.TotalLines
+	badLines += s.bad()
+	stoppedEarly = s.StoppedEarly
+	if runSummary == nil {
+		runSummary = &summary{Source: "aggregate", started: s.started}
+	}
+	runSummary.merge(s)
+	if s.TimedOut {
+		raiseStatus(statusTimedOut)
+	}
+	if s.bad() > 0 || s.Incomplete {
+		output.release()
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Add the counts and outcome of a reported summary to an aggregate.  Line
+// numbers, like the examples and the histogram, only make sense per input
+// and are left out.
+func (s *summary) merge(other *summary) {
+	s.Inputs++
+	s.Counts.Merge(other.Counts)
+	s.Sampled += other.Sampled
+	s.NewlyInvalid += other.NewlyInvalid
+	s.NewlyValid += other.NewlyValid
+	s.ValidOnlyUnderSpec += other.ValidOnlyUnderSpec
+	s.ValidOnlyUnderCompareSpec += other.ValidOnlyUnderCompareSpec
+	s.StoppedEarly = s.StoppedEarly || other.StoppedEarly
+	s.Incomplete = s.Incomplete || other.Incomplete
+	s.TimedOut = s.TimedOut || other.TimedOut
+	for name, count := range other.MissingFields {
+		if s.MissingFields == nil {
+			s.MissingFields = map[string]int{}
+		}
+		s.MissingFields[name] += count
+	}
+	for dataSource, count := range other.DataSources {
+		if s.DataSources == nil {
+			s.DataSources = map[string]int{}
+		}
+		s.DataSources[dataSource] += count
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Write the aggregate of the summaries reported during this run to
+// --report-dir, next to their own reports, when there was more than one.
+func writeAggregateReport() {
+	dir := viper.GetString(ReportDir)
+	if len(dir) == 0 || runSummary == nil || runSummary.Inputs < 2 {
+		return
+	}
+	runSummary.Run = newRunMetadata(runSummary.started)
+	runSummary.writeReportFile(dir, aggregateReportName)
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether the summary is left out of stdout, being clean with
+// --summary-only-on-error.
+func (s *summary) silenced() bool {
+	return viper.GetBool(SummaryOnlyOnError) && s.bad() == 0 && !s.Incomplete
+}
+
+// ----------------------------------------------------------------------------
+
+// Print the summary as JSON to stdout for --report-format json.
+func (s *summary) printReport() {
+	if s.silenced() {
+		return
+	}
+	content, err := s.jsonReport()
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2007, "Error building the JSON report.", err)
+		return
+	}
+	os.Stdout.Write(append(content, '\n'))
+}
+
+// ----------------------------------------------------------------------------
+
+// Print the summary as a CSV row to stdout for --report-format csv.  The
+// header row comes before the first summary of the run, so the rows of a
+// directory or glob make a single table.
+func (s *summary) printCSVRow() {
+	if s.silenced() {
+		return
+	}
+	writer := csv.NewWriter(os.Stdout)
+	if !csvHeaderPrinted {
+		writer.Write(csvColumns)
+		csvHeaderPrinted = true
+	}
+	writer.Write(s.csvRow())
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2015, "Error writing the CSV report.", err)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The --report-format csv row of the summary, in the order of csvColumns.
+func (s *summary) csvRow() []string {
+	bad := s.bad()
+	row := []string{redactURL(s.Source), strconv.Itoa(s.TotalLines), strconv.Itoa(s.validated() - bad), strconv.Itoa(bad)}
+	for _, category := range jsonl.Categories {
+		row = append(row, strconv.Itoa(s.Of(category)))
+	}
+	return row
+}
+
+// ----------------------------------------------------------------------------
+
+// Write the summary as JSON to a file in dir named after the source.
+func (s *summary) writeReport(dir string) {
+	s.writeReportFile(dir, uniqueReportName(s.Source))
+}
+
+// ----------------------------------------------------------------------------
+
+// Write the summary as JSON to the named file in dir.
+func (s *summary) writeReportFile(dir string, name string) {
+	content, err := s.jsonReport()
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2007, "Error building the JSON report.", err)
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2008, "Error creating the report directory.", err)
+		return
+	}
+	reportFile := filepath.Join(dir, name)
+	if err := os.WriteFile(reportFile, append(content, '\n'), 0644); err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 2009, "Error writing the JSON report.", err)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Build a file system safe report file name from a source URL or path,
+// e.g. "https://host/data/file.jsonl" becomes "host_data_file.jsonl.json".
+// Credentials and query strings are left out of the name.
+func reportFileName(source string) string {
+	if u, err := url.Parse(source); err == nil && len(u.Scheme) > 0 {
+		source = u.Host + u.Path
+	}
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(source, "_"), "_.")
+	if len(name) == 0 {
+		name = "report"
+	}
+	return name + ".json"
+}
+
+// ----------------------------------------------------------------------------
+
+// The report file name of a source, unique within the run.  A source whose
+// name is taken by another, as a/b/c.jsonl is by a/b_c.jsonl, gets a -2, -3,
+// ... suffix.  A source reported again keeps its name.
+func uniqueReportName(source string) string {
+	if name, found := reportNames[source]; found {
+		return name
+	}
+	base := strings.TrimSuffix(reportFileName(source), ".json")
+	name := base + ".json"
+	for n := 2; ; n++ {
+		if _, taken := reportSources[strings.ToLower(name)]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s-%d.json", base, n)
+	}
+	reportNames[source] = name
+	reportSources[strings.ToLower(name)] = source
+	return name
+}
+
+// ----------------------------------------------------------------------------
+
+// Capture the context of this run, ending now.
+func newRunMetadata(start time.Time) *runMetadata {
+	hostname, _ := os.Hostname()
+	end := time.Now()
+	return &runMetadata{
+		Version:   buildVersion,
+		Iteration: buildIteration,
+		Hostname:  hostname,
+		Arguments: redactArguments(os.Args[1:]),
+		Start:     start.UTC(),
+		End:       end.UTC(),
+		Duration:  end.Sub(start).Round(time.Millisecond).String(),
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Print the example line numbers of each category.
+func (s *summary) printExamples() {
+	categories := make([]string, 0, len(s.Examples))
+	for category := range s.Examples {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		lines := make([]string, 0, len(s.Examples[category]))
+		for _, lineNumber := range s.Examples[category] {
+			lines = append(lines, strconv.Itoa(lineNumber))
+		}
+		output.Printf("  %s: line(s) %s\n", category, strings.Join(lines, ", "))
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether reading stopped at the --end-line.
+func (s *summary) endOfRange() bool {
+	return s.EndLine > 0 && s.lines >= s.EndLine
+}
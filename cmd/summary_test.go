@@ -0,0 +1,152 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/roncewind/validate/jsonl"
+	"github.com/senzing/senzing-tools/option"
+)
+
+// ----------------------------------------------------------------------------
+
+// Start the reports of a run afresh, as a new process would.
+func resetRunReports(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		totalLines, badLines, runSummary = 0, 0, nil
+		reportNames = map[string]string{}
+		reportSources = map[string]string{aggregateReportName: ""}
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// ----------------------------------------------------------------------------
+
+// A run of several inputs writes a report for each, under names that don't
+// collide, and the aggregate of them all.
+func TestReportDirAggregate(t *testing.T) {
+	inputs := t.TempDir()
+	paths := []string{filepath.Join(inputs, "a", "b_c.jsonl"), filepath.Join(inputs, "a", "b", "c.jsonl")}
+	contents := []string{
+		`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}` + "\n" + `{"DATA_SOURCE":"TEST"}` + "\n",
+		`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}` + "\n" + `{"RECORD_ID":"2"}` + "\n" + `{"DATA_SOURCE":"TEST","RECORD_ID":"3"}` + "\n",
+	}
+	for i, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents[i]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reportDir := t.TempDir()
+	useOptions(t, map[string]interface{}{ReportDir: reportDir, option.InputURL: fileURLs(paths)})
+	captureOutput(t)
+	resetRunReports(t)
+	read()
+	writeAggregateReport()
+
+	entries, err := os.ReadDir(reportDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	first := reportFileName(paths[0])
+	want := []string{aggregateReportName, first, first[:len(first)-len(".json")] + "-2.json"}
+	sort.Strings(want)
+	if len(names) != len(want) {
+		t.Fatalf("the reports are %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("the reports are %v, want %v", names, want)
+		}
+	}
+
+	reports := map[string]summary{}
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(reportDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report summary
+		if err := json.Unmarshal(content, &report); err != nil {
+			t.Fatal(err)
+		}
+		reports[name] = report
+	}
+	if report := reports[first]; report.Source != paths[0] || report.TotalLines != 2 {
+		t.Errorf("%s is the report of %s with %d lines", first, report.Source, report.TotalLines)
+	}
+	aggregate := reports[aggregateReportName]
+	if aggregate.Inputs != 2 || aggregate.TotalLines != 5 || aggregate.Bad != 2 || aggregate.NoRecordId != 1 || aggregate.NoDataSource != 1 || aggregate.Valid {
+		t.Errorf("the aggregate has %d inputs, %d lines, %d bad, %+v", aggregate.Inputs, aggregate.TotalLines, aggregate.Bad, aggregate.Counts)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// A single input has no aggregate report, its own is the same.
+func TestReportDirSingleInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	if err := os.WriteFile(path, []byte(`{"DATA_SOURCE":"TEST","RECORD_ID":"1"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reportDir := t.TempDir()
+	useOptions(t, map[string]interface{}{ReportDir: reportDir, option.InputURL: fileURLs([]string{path})})
+	captureOutput(t)
+	resetRunReports(t)
+	read()
+	writeAggregateReport()
+	if _, err := os.Stat(filepath.Join(reportDir, aggregateReportName)); !os.IsNotExist(err) {
+		t.Errorf("an aggregate report was written for a single input: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(reportDir, reportFileName(path))); err != nil {
+		t.Error(err)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The --report-format csv row has a column for every category, and the
+// category columns add up to bad.
+func TestCSVRowCategories(t *testing.T) {
+	useOptions(t, nil)
+	result := newSummary("test")
+	result.TotalLines = 4
+	for _, category := range []string{categoryNoRecordId, categoryUnknownKeys, categoryLineTooLong} {
+		result.Add(category)
+	}
+	row := result.csvRow()
+	if len(row) != len(csvColumns) {
+		t.Fatalf("%d values for %d columns: %v", len(row), len(csvColumns), row)
+	}
+	columns := map[string]string{}
+	for i, column := range csvColumns {
+		columns[column] = row[i]
+	}
+	sum := 0
+	for _, category := range jsonl.Categories {
+		count, err := strconv.Atoi(columns[category])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += count
+	}
+	if bad := strconv.Itoa(sum); bad != columns["bad"] || bad != "3" {
+		t.Errorf("the categories add up to %s, bad is %s, want 3", bad, columns["bad"])
+	}
+}
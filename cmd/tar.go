@@ -0,0 +1,68 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+)
+
+// ----------------------------------------------------------------------------
+
+// opens a tar archive and validates each JSONL member in it
+func readTarFile(tarFile string) bool {
+	file, err := os.Open(tarFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9057, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer file.Close()
+	validateTarArchive(tarFile, trackProgress(file, fileSize(file)))
+	inputProgress = nil
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate each JSONL member of a tar archive, followed by a total over all
+// the members.  A member is taken to be JSONL by its suffix or, without a
+// recognized one, by its leading bytes.  Directories, links and other
+// members that aren't regular files are skipped.  A tar archive inside a
+// gzip, or other compressed, stream arrives here from scanLines.
+func validateTarArchive(source string, reader io.Reader) {
+	archive := tar.NewReader(reader)
+	members := 0
+	linesBefore, badBefore := totalLines, badLines
+	for !stopRun() {
+		header, err := archive.Next()
+		if err == io.EOF {
+			// read the padding after the end of the archive too, so the size
+			// read can be checked
+			io.Copy(io.Discard, reader)
+			break
+		}
+		if err != nil {
+			readFailed(source, err)
+			break
+		}
+		if !header.FileInfo().Mode().IsRegular() {
+			logger.LogMessage(MessageIdFormat, 53, fmt.Sprintf("Skipping tar member that isn't a regular file: %s", header.Name))
+			continue
+		}
+		member := bufio.NewReader(archive)
+		if memberType := fileTypeOf(header.Name); memberType != "JSONL" && (len(memberType) > 0 || sniffFileType(member) != "JSONL") {
+			logger.LogMessage(MessageIdFormat, 53, fmt.Sprintf("Skipping tar member that isn't JSONL: %s", header.Name))
+			continue
+		}
+		output.Println("tar member:", header.Name)
+		validateLines(source+"/"+header.Name, member)
+		members++
+	}
+	output.Println(passFail(badLines-badBefore, fmt.Sprintf("Validated %d tar members, %d lines in total, %d were bad.", members, totalLines-linesBefore, badLines-badBefore)))
+}
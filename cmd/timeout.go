@@ -0,0 +1,47 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// The context of the requests of the run, canceled when the --timeout
+// passes so a fetch in progress stops at once.
+var runContext = context.Background()
+
+// Whether the --timeout passed, set from the timer's goroutine.
+var timedOut atomic.Bool
+
+// ----------------------------------------------------------------------------
+
+// Start the clock on the --timeout.  Returns the function that stops it.
+func startTimeout() func() {
+	timeout := time.Duration(viper.GetInt(Timeout)) * time.Second
+	if timeout <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	runContext = ctx
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+	return func() {
+		timer.Stop()
+		cancel()
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Whether the run stops before its next input, because --fail-fast stopped
+// it at a bad line or the --timeout passed.
+func stopRun() bool {
+	return failedFast || timedOut.Load()
+}
@@ -0,0 +1,427 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/roncewind/validate/jsonl"
+	"github.com/spf13/viper"
+)
+
+// Categories of invalid lines, as the jsonl package names them.
+const (
+	categoryBadRecord            = jsonl.CategoryBadRecord
+	categoryDisallowedDataSource = jsonl.CategoryDisallowedDataSource
+	categoryDuplicateRecordId    = jsonl.CategoryDuplicateRecordId
+	categoryEmptyDataSource      = jsonl.CategoryEmptyDataSource
+	categoryEmptyRecordId        = jsonl.CategoryEmptyRecordId
+	categoryLineTooLong          = jsonl.CategoryLineTooLong
+	categoryMalformed            = jsonl.CategoryMalformed
+	categoryNoDataSource         = jsonl.CategoryNoDataSource
+	categoryNoRecordId           = jsonl.CategoryNoRecordId
+	categoryNotNormalized        = jsonl.CategoryNotNormalized
+	categorySchemaInvalid        = jsonl.CategorySchemaInvalid
+	categoryUngroupedDataSource  = jsonl.CategoryUngroupedDataSource
+	categoryUnknownFeature       = jsonl.CategoryUnknownFeature
+	categoryUnknownKeys          = jsonl.CategoryUnknownKeys
+	categoryMissingRequiredField = jsonl.CategoryMissingRequiredField
+)
+
+// ----------------------------------------------------------------------------
+
+// lineResult is the outcome of validating a single line.
+type lineResult struct {
+	source string
+	number int
+	// the byte offset the line starts at, -1 when it isn't known
+	offset int64
+	// the byte offset of the line after it, -1 when it isn't known
+	next int64
+	// what the line is when it isn't a line, "Element" of a JSON array or
+	// "Row" of a Parquet file, numbered by its position
+	element string
+	// the trimmed line, aliasing the scanner's buffer when scanned, see text
+	raw      []byte
+	line     string
+	id       jsonl.Identity
+	category string // empty when the line is valid
+	message  string
+	// true for blank lines and lines left out of the sample
+	skipped bool
+	blank   bool
+	// true when the line is longer than --max-line-bytes and wasn't read
+	tooLong bool
+	// true when the line was read after the --timeout passed, a canceled
+	// read may have cut it short
+	late bool
+	// true when the line passed record.Validate and has no empty field
+	recordValid bool
+	// true when the line passed the checks that come before DATA_SOURCE
+	// grouping, which has to be checked in line order
+	groupable bool
+	// the --require-field requirements the record doesn't meet
+	missing []string
+	// fixes found by --suggest-fixes
+	suggestions []string
+	// how the line differs between --schema and --compare-schema
+	drift        string
+	newlyInvalid bool
+	// how the line differs between --spec-version and --compare-spec-version
+	specDrift     string
+	validOnlySpec bool
+}
+
+// ----------------------------------------------------------------------------
+
+// The text of a line.  A scanned line is only copied to a string once its
+// text is needed, most lines are just decoded from the scanner's buffer.
+func (line *lineResult) text() string {
+	if len(line.line) == 0 && len(line.raw) > 0 {
+		line.line = string(line.raw)
+	}
+	return line.line
+}
+
+// ----------------------------------------------------------------------------
+
+// Where a line is, for messages: its number and, when known, the byte offset
+// it starts at.  An array element or Parquet row goes by its position.
+func (line *lineResult) position() string {
+	if len(line.element) > 0 {
+		return fmt.Sprintf("%s %d", line.element, line.number)
+	}
+	if line.offset < 0 {
+		return fmt.Sprintf("Line %d", line.number)
+	}
+	return fmt.Sprintf("Line %d at byte %d", line.number, line.offset)
+}
+
+// ----------------------------------------------------------------------------
+
+// lineChecks holds the options for the checks applied to every line.
+type lineChecks struct {
+	// the record checks, shared with the jsonl package
+	checker *jsonl.Checker
+	// the record checks under --compare-spec-version, nil without it
+	compareChecker *jsonl.Checker
+	ignoreFields   []string
+	suggestFixes   bool
+	maxErrors      int
+	failFast       bool
+	sample         int
+	// the --start-line and --end-line range, 0 when open
+	startLine   int
+	endLine     int
+	printErrors bool
+	quiet       bool
+	verbose     bool
+	// standard field names by their alternate names, nil without a mapping
+	fieldMapping  map[string]string
+	rewriteFields bool
+	workers       int
+	source        string
+	lines         int
+	splitter      *jsonl.Splitter
+	// what the lines are when they aren't lines, see lineResult.element
+	elements string
+}
+
+// ----------------------------------------------------------------------------
+
+// Read the line check options.  State that must carry across streams, like
+// DATA_SOURCE grouping, is kept in the summary.
+func newLineChecks(result *summary) *lineChecks {
+	if viper.GetBool(RequireGroupedDataSource) && result.groups == nil {
+		result.groups = jsonl.NewGroupTracker()
+	}
+	if viper.GetBool(CheckDuplicates) && result.duplicates == nil {
+		result.duplicates = jsonl.NewDuplicateTracker()
+	}
+	ignoreFields := viper.GetStringSlice(IgnoreFields)
+	options := recordOptions(ignoreFields)
+	options.AllowedDataSources = viper.GetStringSlice(AllowedDataSource)
+	options.RequiredFields = viper.GetStringSlice(RequireField)
+	options.RequireNormalized = viper.GetBool(RequireUTF8Normalized)
+	options.NormalizedFields = viper.GetStringSlice(NormalizedFields)
+	if viper.GetBool(Strict) {
+		options.UnknownKeys = recordSpec.unknownKeys
+	}
+	checker := jsonl.NewChecker(options)
+	checker.Groups, checker.Duplicates = result.groups, result.duplicates
+	var compareChecker *jsonl.Checker
+	if compareSpec != nil {
+		options.OptionalRecordId = !compareSpec.requireRecordId
+		if options.UnknownKeys != nil {
+			options.UnknownKeys = compareSpec.unknownKeys
+		}
+		compareChecker = jsonl.NewChecker(options)
+	}
+
+	quiet := viper.GetBool(Quiet) || viper.GetBool(CountOnly)
+	return &lineChecks{
+		checker:        checker,
+		compareChecker: compareChecker,
+		ignoreFields:   ignoreFields,
+		suggestFixes:   viper.GetBool(SuggestFixes),
+		maxErrors:      viper.GetInt(MaxErrors),
+		failFast:       viper.GetBool(FailFast),
+		sample:         viper.GetInt(Sample),
+		startLine:      viper.GetInt(StartLine),
+		endLine:        viper.GetInt(EndLine),
+		printErrors:    len(viper.GetString(ErrorFile)) == 0 && !quiet,
+		quiet:          quiet,
+		verbose:        viper.GetBool(Verbose) && !quiet,
+		fieldMapping:   fieldMapping(),
+		rewriteFields:  viper.GetBool(RewriteMappedFields),
+		workers:        viper.GetInt(Workers),
+		source:         result.Source,
+		lines:          result.lines,
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The checks every record gets, whatever the options of the line checks: the
+// --spec-version, and the --schema and --features-config when given.  The
+// ignored fields are left out of the schema, features and --strict checks.
+func recordOptions(ignoreFields []string) jsonl.Options {
+	options := jsonl.Options{OptionalRecordId: !recordSpec.requireRecordId, IgnoreFields: ignoreFields}
+	if recordSchema != nil {
+		options.Schema = func(line []byte) error { return validateSchema(line, ignoreFields) }
+	}
+	if knownFeatures != nil {
+		options.UnknownFeatures = unknownFeatures
+	}
+	return options
+}
+
+// ----------------------------------------------------------------------------
+
+// Number a trimmed line, given as its bytes and, when already at hand, its
+// text, and decide whether it gets validated.
+func (c *lineChecks) prepareBytes(raw []byte, text string) *lineResult {
+	c.lines++
+	line := &lineResult{source: c.source, number: c.lines, offset: -1, next: -1, element: c.elements, raw: raw, line: text, late: timedOut.Load()}
+	// ignore lines before the --start-line, blank lines, and lines left out
+	// of the sample
+	line.blank = len(raw) == 0
+	line.skipped = line.number < c.startLine || line.blank || (lineSampler != nil && !lineSampler.keep())
+	return line
+}
+
+// ----------------------------------------------------------------------------
+
+// Number the line just scanned, note its byte offset, and decide whether it
+// gets validated.  A line the splitter skipped for being too long is reported
+// as such.  The line is left in the scanner's buffer, valid until the next
+// scan, unless --workers checks it later.
+func (c *lineChecks) prepareScanned(scanner *bufio.Scanner) *lineResult {
+	raw := bytes.TrimSpace(scanner.Bytes())
+	if c.workers > 1 {
+		raw = bytes.Clone(raw)
+	}
+	if c.splitter == nil {
+		return c.prepareBytes(raw, "")
+	}
+	if !c.splitter.TooLong {
+		line := c.prepareBytes(raw, "")
+		line.offset, line.next = c.splitter.Offset, c.splitter.Start
+		return line
+	}
+	c.lines++
+	return &lineResult{
+		source:   c.source,
+		number:   c.lines,
+		offset:   c.splitter.Offset,
+		next:     c.splitter.Start,
+		tooLong:  true,
+		late:     timedOut.Load(),
+		category: categoryLineTooLong,
+		message:  fmt.Sprintf("is longer than --%s, %d bytes", MaxLineBytes, c.splitter.Max),
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Run the checks of a prepared line that don't depend on the lines before
+// it, so lines can be checked concurrently.  This includes the diagnostics
+// of --suggest-fixes, --compare-schema and --compare-spec-version.  The --record-id-field and
+// --data-source-field are renamed to the standard fields first.
+func (c *lineChecks) checkRecord(line *lineResult) {
+	if line.skipped || line.tooLong {
+		return
+	}
+	if c.fieldMapping != nil {
+		if mapped, renamed := mapFields(line.raw, c.fieldMapping); renamed {
+			// the outputs get the line as read, unless --rewrite-mapped-fields
+			if !c.rewriteFields {
+				defer func(raw []byte, text string) { line.raw, line.line = raw, text }(line.raw, line.line)
+			}
+			line.raw, line.line = mapped, ""
+		}
+	}
+	c.validate(line)
+	if c.suggestFixes && !line.recordValid {
+		line.suggestions = suggestFixes(line.text())
+	}
+	if compareSchema != nil && line.recordValid {
+		line.drift, line.newlyInvalid = compareSchemas(line.raw, c.ignoreFields)
+	}
+	if c.compareChecker != nil {
+		c.compareSpecs(line)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Check a validated line under --compare-spec-version as well, noting the
+// drift when it is valid under only one of the two spec versions.  The
+// checks that depend on the lines before are the same under both, so they
+// aren't compared.
+func (c *lineChecks) compareSpecs(line *lineResult) {
+	compared := c.compareChecker.CheckRecord(line.raw)
+	switch {
+	case len(line.category) == 0 && len(compared.Category) > 0:
+		line.validOnlySpec = true
+		line.specDrift = fmt.Sprintf("is valid under --%s %s but not under --%s %s, it %s", SpecVersion, recordSpec.name, CompareSpecVersion, compareSpec.name, compared.Message)
+	case len(line.category) > 0 && len(compared.Category) == 0:
+		line.specDrift = fmt.Sprintf("is valid under --%s %s but not under --%s %s", CompareSpecVersion, compareSpec.name, SpecVersion, recordSpec.name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate a non-blank line, setting its identity, category and message.
+// Only the first failed check is reported, apart from the checks that must
+// be run in line order, left to finish.
+func (c *lineChecks) validate(line *lineResult) {
+	outcome := c.checker.CheckRecord(line.raw)
+	line.id, line.category, line.message = outcome.Identity, outcome.Category, outcome.Message
+	line.recordValid, line.groupable, line.missing = outcome.RecordValid, outcome.Groupable, outcome.Missing
+}
+
+// ----------------------------------------------------------------------------
+
+// Run the checks of a line that depend on the lines before it, DATA_SOURCE
+// grouping and duplicate RECORD_IDs, in line order.
+func (c *lineChecks) checkOrder(line *lineResult) {
+	outcome := jsonl.Outcome{Identity: line.id, Category: line.category, Message: line.message, RecordValid: line.recordValid, Groupable: line.groupable}
+	c.checker.CheckOrder(&outcome, line.number)
+	line.category, line.message = outcome.Category, outcome.Message
+}
+
+// ----------------------------------------------------------------------------
+
+// Accumulate the outcome of a checked line into result, in line order.
+// Returns false once --max-errors, the --sample size or the --end-line is
+// reached, at the first bad line with --fail-fast, or when the --timeout
+// passes, and the stream should be abandoned.
+func (c *lineChecks) finish(result *summary, line *lineResult) bool {
+	if line.late {
+		// only the lines read before the --timeout are counted
+		logger.LogMessage(MessageIdFormat, 61, fmt.Sprintf("Stopped after line %d, the --timeout passed.", line.number-1))
+		result.StoppedEarly, result.TimedOut = true, true
+		return false
+	}
+	result.lines = line.number
+	if line.number < c.startLine {
+		// only numbered, like the lines before a --resume
+		return true
+	}
+	if line.blank {
+		result.BlankLines++
+	} else {
+		result.TotalLines++
+	}
+	if !line.skipped {
+		if lineSampler != nil {
+			result.Sampled++
+		}
+		c.checkOrder(line)
+		result.countDataSource(line.id)
+		if len(line.category) > 0 {
+			output.release()
+			if c.printErrors {
+				output.Println(colored(ansiRed, line.position()+" "+line.message))
+			}
+			result.add(line.category, line.number)
+			result.countMissing(line.missing)
+			if logger.IsWarn() {
+				logger.LogMessageUsingMap(MessageIdFormat, 1002, "Bad line.", map[string]interface{}{
+					"source":   c.source,
+					"line":     line.number,
+					"offset":   line.offset,
+					"category": line.category,
+					"error":    line.message,
+				})
+			}
+			if len(line.suggestions) > 0 && !c.quiet {
+				output.Println(line.position(), "would validate after", strings.Join(line.suggestions, " or "))
+			}
+		} else if c.verbose && line.recordValid {
+			output.Println(line.position(), "is valid, DATA_SOURCE", *line.id.DataSource, "RECORD_ID", jsonl.FieldValue(line.id.RecordId))
+		}
+		if len(line.drift) > 0 {
+			if !c.quiet {
+				output.Println(line.position(), line.drift)
+			}
+			result.addDrift(line.newlyInvalid)
+		}
+		if len(line.specDrift) > 0 {
+			if !c.quiet {
+				output.Println(line.position(), line.specDrift)
+			}
+			result.addSpecDrift(line.validOnlySpec)
+		}
+		recordLine(line)
+	}
+	if runMetrics != nil {
+		runMetrics.countLine(line)
+	}
+	if inputProgress != nil {
+		inputProgress.update(result)
+	}
+	saveCheckpoint(result, line)
+	if c.failFast && result.bad() > 0 {
+		logger.LogMessage(MessageIdFormat, 45, fmt.Sprintf("Stopped at the first bad line, line %d, with --fail-fast.", line.number))
+		result.StoppedEarly = true
+		failedFast = true
+		return false
+	}
+	if c.sample > 0 && result.validated() >= c.sample {
+		logger.LogMessage(MessageIdFormat, 48, fmt.Sprintf("Stopped after a sample of %d lines, the --sample size.", c.sample))
+		result.StoppedEarly = true
+		result.SampleSize = c.sample
+		return false
+	}
+	if c.maxErrors > 0 && result.bad() >= c.maxErrors {
+		logger.LogMessage(MessageIdFormat, 31, fmt.Sprintf("Stopped after %d bad lines, the --max-errors threshold.", result.bad()))
+		result.StoppedEarly = true
+		return false
+	}
+	if c.endLine > 0 && line.number >= c.endLine {
+		logger.LogMessage(MessageIdFormat, 52, fmt.Sprintf("Stopped after line %d, the --end-line.", c.endLine))
+		result.StoppedEarly = true
+		return false
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Check that --start-line and --end-line make a range.
+func checkLineRange() bool {
+	start, end := viper.GetInt(StartLine), viper.GetInt(EndLine)
+	if start < 0 || end < 0 || (end > 0 && end < start) {
+		logger.LogMessage(MessageIdFormat, 2018, fmt.Sprintf("Check the line range, --%s %d and --%s %d.", StartLine, start, EndLine, end))
+		output.Printf("--%s %d and --%s %d are not a range of lines.\n", StartLine, start, EndLine, end)
+		return false
+	}
+	return true
+}
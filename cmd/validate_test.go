@@ -0,0 +1,78 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/roncewind/validate/jsonl"
+)
+
+// ----------------------------------------------------------------------------
+
+// The validate command and jsonl.ValidateReader report the same category for
+// every line, including lines that fail more than one check.
+func TestCommandAndLibraryCategories(t *testing.T) {
+	allowed := []string{"TEST", "OTHER"}
+	required := []string{"NAME_FULL|NAME_ORG"}
+	useOptions(t, map[string]interface{}{
+		AllowedDataSource:        allowed,
+		RequireField:             required,
+		RequireGroupedDataSource: true,
+		RequireUTF8Normalized:    true,
+		CheckDuplicates:          true,
+		Strict:                   true,
+		ExamplesPerCategory:      100,
+	})
+	captureOutput(t)
+
+	file, err := os.Open("testdata/checks.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	result := newSummary("checks.jsonl")
+	scanner, splitter := newLineScanner(file)
+	validateScanner(scanner, splitter, result)
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	library, err := jsonl.ValidateReader(file, jsonl.Options{
+		AllowedDataSources:       allowed,
+		RequiredFields:           required,
+		RequireGroupedDataSource: true,
+		RequireNormalized:        true,
+		CheckDuplicates:          true,
+		UnknownKeys:              recordSpec.unknownKeys,
+		ExamplesPerCategory:      100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]int{
+		categoryDuplicateRecordId:    {2},
+		categoryDisallowedDataSource: {3},
+		categoryUnknownKeys:          {4, 9},
+		categoryMissingRequiredField: {5},
+		categoryUngroupedDataSource:  {7},
+		categoryNotNormalized:        {8},
+		categoryMalformed:            {10},
+		categoryEmptyDataSource:      {11},
+		categoryNoDataSource:         {12},
+		categoryNoRecordId:           {14},
+	}
+	if !reflect.DeepEqual(result.Examples, want) {
+		t.Errorf("the validate command found %v, want %v", result.Examples, want)
+	}
+	if !reflect.DeepEqual(library.Examples, result.Examples) {
+		t.Errorf("jsonl.ValidateReader found %v, the validate command %v", library.Examples, result.Examples)
+	}
+	if library.Counts != result.Counts {
+		t.Errorf("jsonl.ValidateReader counted %+v, the validate command %+v", library.Counts, result.Counts)
+	}
+}
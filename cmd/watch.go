@@ -0,0 +1,116 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+)
+
+// ----------------------------------------------------------------------------
+
+// Monitor an append-only JSONL resource.  The resource is re-fetched every
+// --watch-interval seconds starting from the last validated byte offset and
+// only the newly appended lines are validated.  Counts are kept across
+// fetches and the final summary is reported when interrupted, or when the
+// --timeout passes.
+func watchJSONLResource(jsonURL string) bool {
+	interval := time.Duration(viper.GetInt(WatchInterval)) * time.Second
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	result := newSummary(jsonURL)
+	var offset int64
+	for {
+		consumed, err := fetchAppended(jsonURL, offset, result)
+		if err != nil && timedOut.Load() {
+			result.StoppedEarly, result.TimedOut = true, true
+		} else if err != nil {
+			logger.LogMessageFromError(MessageIdFormat, 2005, "Error fetching appended lines from inputURL.", err)
+		}
+		if consumed > 0 {
+			offset += consumed
+			output.Printf("Validated %d lines, %d were bad.\n", result.TotalLines, result.bad())
+			output.Flush()
+		}
+		if result.StoppedEarly {
+			result.report()
+			return true
+		}
+		select {
+		case <-signals:
+			result.report()
+			return true
+		case <-runContext.Done():
+			result.StoppedEarly, result.TimedOut = true, true
+			result.report()
+			return true
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Fetch the resource from offset onward and validate every complete line.
+// Returns the number of bytes consumed.
+func fetchAppended(jsonURL string, offset int64, result *summary) (int64, error) {
+	request, err := newResourceRequest(jsonURL)
+	if err != nil {
+		return 0, err
+	}
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// nothing has been appended since the last fetch
+		return 0, nil
+	case http.StatusOK:
+		// the server ignored the Range header, skip what was already validated
+		if _, err := io.CopyN(io.Discard, response.Body, offset); err != nil {
+			if err == io.EOF {
+				return 0, nil
+			}
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unexpected HTTP status: %s", response.Status)
+	}
+
+	return validateAppended(response.Body, offset, result)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate every complete line of what was appended to an input, read from
+// the byte offset onward.  Returns the number of bytes consumed, a trailing
+// partial line is left for the next read.  A line longer than
+// --max-line-bytes is consumed as a lineTooLong once its newline is read.
+func validateAppended(reader io.Reader, offset int64, result *summary) (int64, error) {
+	scanner, splitter := newLineScanner(reader)
+	splitter.Resume(offset)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		// the end of what was read isn't the end of a line
+		return splitter.Split(data, false)
+	})
+	validateScanner(scanner, splitter, result)
+	return splitter.Start - offset, scanner.Err()
+}
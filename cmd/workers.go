@@ -0,0 +1,62 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bufio"
+	"sync"
+)
+
+// Lines read ahead for each worker of --workers.
+const linesPerWorker = 256
+
+// ----------------------------------------------------------------------------
+
+// Validate the lines from the scanner with --workers goroutines.  Lines are
+// read in batches, the order independent checks of a batch run concurrently,
+// then the outcomes are accumulated in line order so line numbers, counts
+// and output match a sequential run.
+func validateConcurrently(scanner *bufio.Scanner, checks *lineChecks, result *summary) {
+	batch := make([]*lineResult, 0, checks.workers*linesPerWorker)
+	for scanner.Scan() {
+		batch = append(batch, checks.prepareScanned(scanner))
+		if len(batch) == cap(batch) {
+			if !checks.validateBatch(result, batch) {
+				return
+			}
+			batch = batch[:0]
+		}
+	}
+	checks.validateBatch(result, batch)
+}
+
+// ----------------------------------------------------------------------------
+
+// Check a batch of prepared lines concurrently and accumulate the outcomes.
+// Returns false once --max-errors is reached.
+func (c *lineChecks) validateBatch(result *summary, batch []*lineResult) bool {
+	lines := make(chan *lineResult)
+	var workers sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for line := range lines {
+				c.checkRecord(line)
+			}
+		}()
+	}
+	for _, line := range batch {
+		lines <- line
+	}
+	close(lines)
+	workers.Wait()
+
+	for _, line := range batch {
+		if !c.finish(result, line) {
+			return false
+		}
+	}
+	return true
+}
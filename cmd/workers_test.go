@@ -0,0 +1,103 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A bad line as finish prints it.
+var badLinePattern = regexp.MustCompile(`^Line (\d+) at byte (\d+) (.+)$`)
+
+// ----------------------------------------------------------------------------
+
+// Validate a stream with many bad lines on many workers.  Every bad line is
+// reported once, whole and in line order, the same as a sequential run.
+func TestValidateConcurrentlyOutputLines(t *testing.T) {
+	const lines = 5000
+	var input strings.Builder
+	bad := map[int]bool{}
+	for number := 1; number <= lines; number++ {
+		switch number % 3 {
+		case 0:
+			fmt.Fprintf(&input, "{\"DATA_SOURCE\":\"TEST\",\"RECORD_ID\":\"%d\"}\n", number)
+		case 1:
+			fmt.Fprintf(&input, "{\"DATA_SOURCE\":\"TEST\",\"RECORD_ID\":%d\n", number)
+			bad[number] = true
+		case 2:
+			fmt.Fprintf(&input, "{\"DATA_SOURCE\":\"\",\"RECORD_ID\":\"%d\"}\n", number)
+			bad[number] = true
+		}
+	}
+	for _, workers := range []int{1, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			useOptions(t, map[string]interface{}{Workers: workers})
+			captured := captureOutput(t)
+			validateLines("test", strings.NewReader(input.String()))
+			output.Flush()
+
+			previous := 0
+			reported := 0
+			for _, text := range strings.Split(strings.TrimSuffix(captured.String(), "\n"), "\n") {
+				match := badLinePattern.FindStringSubmatch(text)
+				if match == nil {
+					continue
+				}
+				number, _ := strconv.Atoi(match[1])
+				if !bad[number] || number <= previous {
+					t.Fatalf("line %d reported out of order or when valid: %q", number, text)
+				}
+				// the record package's messages are JSON objects
+				var message map[string]interface{}
+				if strings.HasPrefix(match[3], "{") && json.NewDecoder(strings.NewReader(match[3])).Decode(&message) != nil {
+					t.Fatalf("line %d has a broken message: %q", number, text)
+				}
+				previous = number
+				reported++
+			}
+			if reported != len(bad) {
+				t.Fatalf("%d bad lines reported, want %d:\n%s", reported, len(bad), captured.String())
+			}
+			if !strings.Contains(captured.String(), fmt.Sprintf("Validated %d lines, %d were bad.", lines, len(bad))) {
+				t.Fatalf("no summary of %d bad lines in:\n%s", len(bad), captured.String())
+			}
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Lines printed from many goroutines at once come out whole.
+func TestSyncWriterConcurrentLines(t *testing.T) {
+	captured := captureOutput(t)
+	var writers sync.WaitGroup
+	for writer := 0; writer < 16; writer++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for line := 0; line < 500; line++ {
+				output.Printf("writer %d line %d %s\n", writer, line, strings.Repeat("x", 100))
+			}
+		}()
+	}
+	writers.Wait()
+	output.Flush()
+
+	printed := strings.Split(strings.TrimSuffix(captured.String(), "\n"), "\n")
+	if len(printed) != 16*500 {
+		t.Fatalf("%d lines printed, want %d", len(printed), 16*500)
+	}
+	whole := regexp.MustCompile(`^writer \d+ line \d+ x{100}$`)
+	for _, text := range printed {
+		if !whole.MatchString(text) {
+			t.Fatalf("interleaved line %q", text)
+		}
+	}
+}
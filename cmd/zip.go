@@ -0,0 +1,152 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docktermj/go-xyzzy-helpers/logger"
+	"github.com/spf13/viper"
+	"github.com/yeka/zip"
+)
+
+// ----------------------------------------------------------------------------
+
+// opens a zip archive and validates each JSONL entry in it
+func readZipFile(zipFile string) bool {
+	archive, err := zip.OpenReader(zipFile)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9014, "Fatal error opening inputURL.", err)
+		return false
+	}
+	defer archive.Close()
+	return validateZipArchive(zipFile, &archive.Reader)
+}
+
+// ----------------------------------------------------------------------------
+
+// retrieves a zip archive and validates each JSONL entry in it
+func readZipResource(zipURL string) bool {
+	response, err := getResource(zipURL)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9040, "Fatal error retrieving inputURL.", err)
+		return false
+	}
+	defer response.Body.Close()
+	body := &countingReader{reader: response.Body}
+	if !readZipStream(zipURL, trackProgress(body, response.ContentLength)) {
+		return false
+	}
+	return checkContentLength(response, body)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate a zip archive that arrives as a stream.  A zip archive has its
+// directory at the end and needs random access, so the stream is spooled to
+// a temporary file first.
+func readZipStream(source string, reader io.Reader) bool {
+	spool, err := os.CreateTemp("", "validate-*.zip")
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9041, "Fatal error creating a temporary file for the zip archive.", err)
+		return false
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9040, "Fatal error retrieving inputURL.", err)
+		output.Println("Unable to download the zip archive:", err)
+		return false
+	}
+	inputProgress = nil
+	archive, err := zip.NewReader(spool, size)
+	if err != nil {
+		logger.LogMessageFromError(MessageIdFormat, 9014, "Fatal error opening inputURL.", err)
+		output.Println("Unable to open the zip archive:", err)
+		return false
+	}
+	return validateZipArchive(source, archive)
+}
+
+// ----------------------------------------------------------------------------
+
+// Validate each JSONL entry of an archive, followed by a total over all the
+// entries.
+func validateZipArchive(source string, archive *zip.Reader) bool {
+	password := viper.GetString(ZipPassword)
+	entries := 0
+	linesBefore, badBefore := totalLines, badLines
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !strings.HasSuffix(entry.Name, "jsonl") {
+			logger.LogMessage(MessageIdFormat, 18, fmt.Sprintf("Skipping zip entry: %s", entry.Name))
+			continue
+		}
+		output.Println("zip entry:", entry.Name)
+		if !readZipEntry(source, entry, password) {
+			return false
+		}
+		entries++
+		if stopRun() {
+			break
+		}
+	}
+	output.Println(passFail(badLines-badBefore, fmt.Sprintf("Validated %d zip entries, %d lines in total, %d were bad.", entries, totalLines-linesBefore, badLines-badBefore)))
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Decrypt, when needed, and validate a single zip entry.
+func readZipEntry(zipFile string, entry *zip.File, password string) bool {
+	if entry.IsEncrypted() {
+		if len(password) == 0 {
+			logger.LogMessage(MessageIdFormat, 9015, fmt.Sprintf("Fatal error, zip entry %s is encrypted, use --zip-password.", entry.Name))
+			output.Println("Zip entry", entry.Name, "is encrypted, use --zip-password.")
+			return false
+		}
+		entry.SetPassword(password)
+	}
+	entryReader, err := entry.Open()
+	if err != nil {
+		return zipEntryError(entry, err)
+	}
+	defer entryReader.Close()
+
+	var reader io.Reader = entryReader
+	if entry.IsEncrypted() {
+		// A wrong ZipCrypto password only shows up as a checksum error at the
+		// end of the entry, so decrypt it completely before validating.
+		content, err := io.ReadAll(entryReader)
+		if err != nil {
+			return zipEntryError(entry, err)
+		}
+		reader = bytes.NewReader(content)
+	}
+	validateLines(zipFile+"/"+entry.Name, reader)
+	return true
+}
+
+// ----------------------------------------------------------------------------
+
+// Log why a zip entry couldn't be read, calling out a wrong password.  With
+// ZipCrypto a wrong password yields garbage that fails to decompress or fails
+// the checksum, so any read error of an encrypted entry is reported as such.
+func zipEntryError(entry *zip.File, err error) bool {
+	if errors.Is(err, zip.ErrPassword) || errors.Is(err, zip.ErrAuthentication) || entry.IsEncrypted() {
+		logger.LogMessageFromError(MessageIdFormat, 9016, fmt.Sprintf("Fatal error, wrong --zip-password for zip entry %s.", entry.Name), err)
+		output.Println("Wrong --zip-password for zip entry", entry.Name)
+		return false
+	}
+	logger.LogMessageFromError(MessageIdFormat, 9017, fmt.Sprintf("Fatal error reading zip entry %s.", entry.Name), err)
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Checker runs the checks of Options on one line at a time, the checks both
+// ValidateReader and the validate command apply.  Only the first failed check
+// of a line is reported, in this order: an empty DATA_SOURCE or RECORD_ID,
+// the record itself, the Schema, the UnknownFeatures, the AllowedDataSources,
+// the UnknownKeys, the RequiredFields, the DATA_SOURCE grouping and NFC
+// normalization, and last a duplicate RECORD_ID.
+type Checker struct {
+	options            Options
+	allowedDataSources map[string]bool
+	// the state carried from line to line, set by NewChecker from the
+	// Options, shared when several streams are checked as one
+	Groups     *GroupTracker
+	Duplicates *DuplicateTracker
+}
+
+// Outcome is what a Checker found of a non-blank line.
+type Outcome struct {
+	Identity Identity
+	// empty when the line is valid
+	Category string
+	Message  string
+	// true when the line passed record.Validate and has no empty field
+	RecordValid bool
+	// true when the line passed the checks that come before DATA_SOURCE
+	// grouping, which has to be checked in line order
+	Groupable bool
+	// the RequiredFields the record doesn't meet
+	Missing []string
+}
+
+// ----------------------------------------------------------------------------
+
+// NewChecker returns a Checker for the checks of options.
+func NewChecker(options Options) *Checker {
+	c := &Checker{options: options}
+	if len(options.AllowedDataSources) > 0 {
+		c.allowedDataSources = make(map[string]bool, len(options.AllowedDataSources))
+		for _, code := range options.AllowedDataSources {
+			c.allowedDataSources[strings.ToUpper(strings.TrimSpace(code))] = true
+		}
+	}
+	if options.RequireGroupedDataSource {
+		c.Groups = NewGroupTracker()
+	}
+	if options.CheckDuplicates {
+		c.Duplicates = NewDuplicateTracker()
+	}
+	return c
+}
+
+// ----------------------------------------------------------------------------
+
+// CheckRecord runs the checks of a non-blank line that don't depend on the
+// lines before it, so lines can be checked concurrently.  CheckOrder finishes
+// the checks.
+func (c *Checker) CheckRecord(line []byte) Outcome {
+	requireRecordId := !c.options.OptionalRecordId
+	valid, id, err := ValidateRecord(line, requireRecordId)
+	outcome := Outcome{Identity: id}
+	if field := id.EmptyField(requireRecordId); len(field) > 0 {
+		outcome.Message = "has an empty " + field + " field"
+		if field == "RECORD_ID" {
+			outcome.Category = CategoryEmptyRecordId
+		} else {
+			outcome.Category = CategoryEmptyDataSource
+		}
+		return outcome
+	}
+	if !valid {
+		outcome.Category, outcome.Message = CategoryBadRecord, "did not validate"
+		if err != nil {
+			outcome.Category, outcome.Message = ClassifyError(err), err.Error()
+			var malformed *MalformedError
+			if errors.As(err, &malformed) {
+				outcome.Message += ", " + malformed.Where()
+			}
+		}
+		return outcome
+	}
+	outcome.RecordValid = true
+
+	var fields map[string]interface{}
+	if len(c.options.RequiredFields) > 0 || c.options.RequireNormalized || c.options.UnknownFeatures != nil || c.options.UnknownKeys != nil {
+		fields, _ = ParseRecord(line)
+	}
+	if c.options.Schema != nil {
+		if err := c.options.Schema(line); err != nil {
+			outcome.Category, outcome.Message = CategorySchemaInvalid, err.Error()
+			return outcome
+		}
+	}
+	checked := withoutFields(fields, c.options.IgnoreFields)
+	if c.options.UnknownFeatures != nil {
+		if unknown := c.options.UnknownFeatures(checked); len(unknown) > 0 {
+			outcome.Category, outcome.Message = CategoryUnknownFeature, "has unknown feature(s) "+strings.Join(unknown, ", ")
+			return outcome
+		}
+	}
+	if c.allowedDataSources != nil && !c.allowedDataSources[strings.ToUpper(*id.DataSource)] {
+		outcome.Category = CategoryDisallowedDataSource
+		outcome.Message = "DATA_SOURCE " + *id.DataSource + " is not one of the allowed codes"
+		return outcome
+	}
+	if c.options.UnknownKeys != nil {
+		if unknown := c.options.UnknownKeys(checked); len(unknown) > 0 {
+			outcome.Category, outcome.Message = CategoryUnknownKeys, "has key(s) not in the Generic Entity Specification "+strings.Join(unknown, ", ")
+			return outcome
+		}
+	}
+	if missing := MissingFields(fields, c.options.RequiredFields); len(missing) > 0 {
+		outcome.Category = CategoryMissingRequiredField
+		outcome.Message = "is missing required field(s) " + strings.Join(missing, ", ")
+		outcome.Missing = missing
+		return outcome
+	}
+	outcome.Groupable = true
+	if c.options.RequireNormalized {
+		if field, ok := IsNormalized(fields, c.options.NormalizedFields); !ok {
+			outcome.Category, outcome.Message = CategoryNotNormalized, "field "+field+" is not NFC-normalized"
+		}
+	}
+	return outcome
+}
+
+// ----------------------------------------------------------------------------
+
+// CheckOrder runs the checks that depend on the lines before, DATA_SOURCE
+// grouping and duplicate RECORD_IDs, so it must be called in line order.  A
+// break in the grouping takes precedence over NFC normalization.  Every
+// record with an identity is tracked, but a duplicate is only reported when
+// no other check failed.
+func (c *Checker) CheckOrder(outcome *Outcome, lineNumber int) {
+	id := outcome.Identity
+	if c.Groups != nil && outcome.Groupable && c.Groups.Breaks(*id.DataSource) {
+		outcome.Category = CategoryUngroupedDataSource
+		outcome.Message = "DATA_SOURCE " + *id.DataSource + " reappears after " + c.Groups.Previous + " so records are not grouped by DATA_SOURCE"
+	}
+	if c.Duplicates == nil || !outcome.RecordValid || id.RecordId == nil || len(*id.RecordId) == 0 {
+		return
+	}
+	if first := c.Duplicates.Duplicates(id, lineNumber); first > 0 && len(outcome.Category) == 0 {
+		outcome.Category = CategoryDuplicateRecordId
+		outcome.Message = fmt.Sprintf("RECORD_ID %s of DATA_SOURCE %s duplicates line %d", *id.RecordId, *id.DataSource, first)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The fields without the ignored keys, the fields themselves when none of
+// them is there.
+func withoutFields(fields map[string]interface{}, ignored []string) map[string]interface{} {
+	var kept map[string]interface{}
+	for _, key := range ignored {
+		if _, found := fields[key]; !found {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]interface{}, len(fields))
+			for name, value := range fields {
+				kept[name] = value
+			}
+		}
+		delete(kept, key)
+	}
+	if kept == nil {
+		return fields
+	}
+	return kept
+}
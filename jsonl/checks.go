@@ -0,0 +1,133 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ----------------------------------------------------------------------------
+
+// ParseRecord unmarshals a JSON-line into its top-level fields.
+func ParseRecord(line []byte) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	err := json.Unmarshal(line, &fields)
+	return fields, err
+}
+
+// ----------------------------------------------------------------------------
+
+// MissingFields returns the requirements a record doesn't meet.  A
+// requirement is a top-level key, or keys separated by | of which any one
+// will do.  A key that is null or an empty string counts as missing.
+func MissingFields(fields map[string]interface{}, required []string) []string {
+	missing := []string{}
+	for _, requirement := range required {
+		met := false
+		for _, key := range strings.Split(requirement, "|") {
+			value, found := fields[strings.TrimSpace(key)]
+			if text, isText := value.(string); found && value != nil && (!isText || len(strings.TrimSpace(text)) > 0) {
+				met = true
+				break
+			}
+		}
+		if !met {
+			missing = append(missing, requirement)
+		}
+	}
+	return missing
+}
+
+// ----------------------------------------------------------------------------
+
+// IsNormalized checks that the given text fields are in Unicode NFC form.
+// When no fields are given, every top-level string field is checked.
+// Returns the name of the first field that is not normalized.
+func IsNormalized(fields map[string]interface{}, names []string) (string, bool) {
+	if len(names) == 0 {
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		text, ok := fields[name].(string)
+		if ok && !norm.NFC.IsNormalString(text) {
+			return name, false
+		}
+	}
+	return "", true
+}
+
+// ----------------------------------------------------------------------------
+
+// GroupTracker follows the DATA_SOURCE of consecutive records to check that
+// each DATA_SOURCE appears as a single contiguous group.
+type GroupTracker struct {
+	current string
+	// the DATA_SOURCE before the current one
+	Previous string
+	seen     map[string]bool
+}
+
+// ----------------------------------------------------------------------------
+
+func NewGroupTracker() *GroupTracker {
+	return &GroupTracker{seen: map[string]bool{}}
+}
+
+// ----------------------------------------------------------------------------
+
+// Breaks adds the next record's DATA_SOURCE, returns true when it already
+// appeared before a different DATA_SOURCE, which is then left in Previous.
+func (g *GroupTracker) Breaks(dataSource string) bool {
+	if dataSource == g.current {
+		return false
+	}
+	reappears := g.seen[dataSource]
+	g.seen[dataSource] = true
+	g.Previous = g.current
+	g.current = dataSource
+	return reappears
+}
+
+// ----------------------------------------------------------------------------
+
+// DuplicateTracker remembers the line of each (DATA_SOURCE, RECORD_ID) pair
+// seen.  To keep memory down on large inputs, pairs are held as 64-bit
+// hashes, about 40 bytes per distinct record rather than the strings
+// themselves.  The chance of two different pairs colliding is negligible,
+// under one in a million even at a hundred million records.
+type DuplicateTracker struct {
+	lines map[uint64]int
+}
+
+// ----------------------------------------------------------------------------
+
+func NewDuplicateTracker() *DuplicateTracker {
+	return &DuplicateTracker{lines: map[uint64]int{}}
+}
+
+// ----------------------------------------------------------------------------
+
+// Duplicates adds the identity of a record on a line, returns the line the
+// identity was first seen on when it is a duplicate, otherwise 0.
+// DATA_SOURCE is compared case-insensitively as it is upper cased on load.
+func (d *DuplicateTracker) Duplicates(id Identity, lineNumber int) int {
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.ToUpper(*id.DataSource)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(*id.RecordId))
+	key := hash.Sum64()
+	if first, seen := d.lines[key]; seen {
+		return first
+	}
+	d.lines[key] = lineNumber
+	return 0
+}
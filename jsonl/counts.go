@@ -0,0 +1,125 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+// Counts are the lines validated and the bad lines of each category, named
+// as in the JSON summary.
+type Counts struct {
+	TotalLines           int `json:"totalLines"`
+	BlankLines           int `json:"blankLines"`
+	NoRecordId           int `json:"noRecordId"`
+	NoDataSource         int `json:"noDataSource"`
+	EmptyRecordId        int `json:"emptyRecordId"`
+	EmptyDataSource      int `json:"emptyDataSource"`
+	Malformed            int `json:"malformed"`
+	BadRecord            int `json:"badRecord"`
+	NotNormalized        int `json:"notNormalized"`
+	SchemaInvalid        int `json:"schemaInvalid"`
+	UngroupedDataSource  int `json:"ungroupedDataSource"`
+	UnknownFeature       int `json:"unknownFeature"`
+	UnknownKeys          int `json:"unknownKeys"`
+	DisallowedDataSource int `json:"disallowedDataSource"`
+	DuplicateRecordId    int `json:"duplicateRecordId"`
+	LineTooLong          int `json:"lineTooLong"`
+	MissingRequiredField int `json:"missingRequiredField"`
+}
+
+// Categories are the categories of invalid lines, in the order of Counts.
+var Categories = []string{
+	CategoryNoRecordId,
+	CategoryNoDataSource,
+	CategoryEmptyRecordId,
+	CategoryEmptyDataSource,
+	CategoryMalformed,
+	CategoryBadRecord,
+	CategoryNotNormalized,
+	CategorySchemaInvalid,
+	CategoryUngroupedDataSource,
+	CategoryUnknownFeature,
+	CategoryUnknownKeys,
+	CategoryDisallowedDataSource,
+	CategoryDuplicateRecordId,
+	CategoryLineTooLong,
+	CategoryMissingRequiredField,
+}
+
+// ----------------------------------------------------------------------------
+
+// BadLines is the number of lines that failed validation for any reason.
+func (c *Counts) BadLines() int {
+	bad := 0
+	for _, category := range Categories {
+		bad += *c.count(category)
+	}
+	return bad
+}
+
+// ----------------------------------------------------------------------------
+
+// Add counts an invalid line in its category.
+func (c *Counts) Add(category string) {
+	if count := c.count(category); count != nil {
+		*count++
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// Of is the number of lines counted in a category.
+func (c *Counts) Of(category string) int {
+	if count := c.count(category); count != nil {
+		return *count
+	}
+	return 0
+}
+
+// ----------------------------------------------------------------------------
+
+// Merge adds the counts of other, as of another stream.
+func (c *Counts) Merge(other Counts) {
+	c.TotalLines += other.TotalLines
+	c.BlankLines += other.BlankLines
+	for _, category := range Categories {
+		*c.count(category) += other.Of(category)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The count of a category, nil for an unknown category.
+func (c *Counts) count(category string) *int {
+	switch category {
+	case CategoryNoRecordId:
+		return &c.NoRecordId
+	case CategoryNoDataSource:
+		return &c.NoDataSource
+	case CategoryEmptyRecordId:
+		return &c.EmptyRecordId
+	case CategoryEmptyDataSource:
+		return &c.EmptyDataSource
+	case CategoryMalformed:
+		return &c.Malformed
+	case CategoryBadRecord:
+		return &c.BadRecord
+	case CategoryNotNormalized:
+		return &c.NotNormalized
+	case CategorySchemaInvalid:
+		return &c.SchemaInvalid
+	case CategoryUngroupedDataSource:
+		return &c.UngroupedDataSource
+	case CategoryUnknownFeature:
+		return &c.UnknownFeature
+	case CategoryUnknownKeys:
+		return &c.UnknownKeys
+	case CategoryDisallowedDataSource:
+		return &c.DisallowedDataSource
+	case CategoryDuplicateRecordId:
+		return &c.DuplicateRecordId
+	case CategoryLineTooLong:
+		return &c.LineTooLong
+	case CategoryMissingRequiredField:
+		return &c.MissingRequiredField
+	}
+	return nil
+}
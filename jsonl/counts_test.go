@@ -0,0 +1,40 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+
+// Every category is counted by Add, read by Of, part of BadLines and added
+// by Merge.
+func TestCountsCategories(t *testing.T) {
+	counts := Counts{TotalLines: 2 * len(Categories), BlankLines: 1}
+	for i, category := range Categories {
+		for n := 0; n <= i; n++ {
+			counts.Add(category)
+		}
+	}
+	bad := 0
+	for i, category := range Categories {
+		if counts.Of(category) != i+1 {
+			t.Errorf("%s counted %d, want %d", category, counts.Of(category), i+1)
+		}
+		bad += i + 1
+	}
+	if counts.BadLines() != bad {
+		t.Errorf("BadLines is %d, want %d", counts.BadLines(), bad)
+	}
+	merged := counts
+	merged.Merge(counts)
+	if merged.BadLines() != 2*bad || merged.TotalLines != 2*counts.TotalLines || merged.BlankLines != 2 {
+		t.Errorf("merged %+v into itself, got %+v", counts, merged)
+	}
+	counts.Add("unknown")
+	if counts.BadLines() != bad || counts.Of("unknown") != 0 {
+		t.Errorf("an unknown category was counted")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// UTF8BOM is the UTF-8 encoding of U+FEFF, the byte order mark.
+var UTF8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Splitter splits a stream into lines like bufio.ScanLines, but a line
+// longer than Max bytes is skipped over rather than ending the scan.  Such a
+// line is returned empty, with TooLong set until the next line is split.
+// A UTF-8 byte order mark at the start of the stream is skipped.
+type Splitter struct {
+	Max        int
+	TooLong    bool
+	discarding bool
+	// the byte offset of the line last returned, counting the newlines
+	Offset int64
+	// the byte offsets of the next line and of the data
+	Start    int64
+	consumed int64
+}
+
+// ----------------------------------------------------------------------------
+
+// NewScanner is a scanner of the lines of reader, with lines up to
+// maxLineBytes.
+func NewScanner(reader io.Reader, maxLineBytes int) (*bufio.Scanner, *Splitter) {
+	splitter := &Splitter{Max: maxLineBytes}
+	scanner := bufio.NewScanner(reader)
+	// room for a line one byte too long, after a byte order mark
+	size := splitter.Max + 1 + len(UTF8BOM)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, size)), size)
+	scanner.Split(splitter.Split)
+	return scanner, splitter
+}
+
+// ----------------------------------------------------------------------------
+
+// Resume counts the byte offsets from offset, for a stream opened there.
+func (s *Splitter) Resume(offset int64) {
+	s.consumed, s.Start = offset, offset
+}
+
+// ----------------------------------------------------------------------------
+
+// Split is the bufio.SplitFunc of a Splitter, keeping track of the byte
+// offset of each line.
+func (s *Splitter) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if s.consumed == 0 && bytes.HasPrefix(UTF8BOM, data) && len(data) < len(UTF8BOM) && !atEOF {
+		// request more data
+		return 0, nil, nil
+	}
+	// the mark goes with the first line, at EOF the scanner stops at the
+	// first advance without a token
+	bom := 0
+	if s.consumed == 0 && bytes.HasPrefix(data, UTF8BOM) {
+		bom = len(UTF8BOM)
+		s.Start = int64(bom)
+	}
+	advance, token, err := s.splitLine(data[bom:], atEOF)
+	if advance > 0 || token != nil {
+		advance += bom
+	}
+	s.consumed += int64(advance)
+	if token != nil {
+		s.Offset, s.Start = s.Start, s.consumed
+	}
+	return advance, token, err
+}
+
+// ----------------------------------------------------------------------------
+
+// Split the next line.  Once more than Max bytes are buffered without a
+// newline, they are dropped and the rest of the line is discarded as it is
+// read.
+func (s *Splitter) splitLine(data []byte, atEOF bool) (int, []byte, error) {
+	s.TooLong = false
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		if s.discarding || i > s.Max {
+			return i + 1, s.skipped(), nil
+		}
+		return bufio.ScanLines(data, atEOF)
+	}
+	if atEOF {
+		if s.discarding || len(data) > s.Max {
+			return len(data), s.skipped(), nil
+		}
+		return bufio.ScanLines(data, atEOF)
+	}
+	if len(data) > s.Max {
+		s.discarding = true
+		return len(data), nil, nil
+	}
+	// request more data
+	return 0, nil, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Finish skipping a line that is too long, returning its empty token.
+func (s *Splitter) skipped() []byte {
+	s.discarding = false
+	s.TooLong = true
+	return []byte{}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+
+// Package jsonl validates JSON-lines of records against the Generic Entity
+// Specification, the checks behind the validate command.  ValidateReader
+// validates a whole stream and a Checker one line at a time, the other
+// functions are the checks they are made of, for programs that read their
+// records some other way.
+package jsonl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/senzing/go-common/record"
+)
+
+// Categories of invalid lines, named as in the JSON summary.
+const (
+	CategoryBadRecord            = "badRecord"
+	CategoryDisallowedDataSource = "disallowedDataSource"
+	CategoryDuplicateRecordId    = "duplicateRecordId"
+	CategoryEmptyDataSource      = "emptyDataSource"
+	CategoryEmptyRecordId        = "emptyRecordId"
+	CategoryLineTooLong          = "lineTooLong"
+	CategoryMalformed            = "malformed"
+	CategoryNoDataSource         = "noDataSource"
+	CategoryNoRecordId           = "noRecordId"
+	CategoryNotNormalized        = "notNormalized"
+	CategorySchemaInvalid        = "schemaInvalid"
+	CategoryUngroupedDataSource  = "ungroupedDataSource"
+	CategoryUnknownFeature       = "unknownFeature"
+	CategoryUnknownKeys          = "unknownKeys"
+	CategoryMissingRequiredField = "missingRequiredField"
+)
+
+// The category of each record package message, by its number in
+// record.IdMessages.
+var recordErrorCategories = map[int]string{
+	3000: CategoryMalformed,
+	3001: CategoryNoDataSource,
+	3002: CategoryNoRecordId,
+}
+
+// ----------------------------------------------------------------------------
+
+// Identity holds the fields that identify a record, nil when absent.
+type Identity struct {
+	DataSource *string `json:"DATA_SOURCE"`
+	RecordId   *string `json:"RECORD_ID"`
+}
+
+// recordFields are the fields record.Validate decodes, with the identity
+// kept as pointers to tell an absent field from an empty one.  Json matches
+// the untagged field of record.Record, so both fail on the same lines.
+type recordFields struct {
+	Identity
+	Json *string
+}
+
+// MalformedError is the record.Validate error of a line that doesn't decode
+// as a record, with where the decoding failed.
+type MalformedError struct {
+	err error
+	// the byte of the line the decoding failed at, counting from 1
+	Offset int64
+	// why the decoding failed, like invalid character 'x' after object key
+	Reason string
+}
+
+// ----------------------------------------------------------------------------
+
+// FieldValue is the value of an optional field, empty when it is absent.
+func FieldValue(field *string) string {
+	if field == nil {
+		return ""
+	}
+	return *field
+}
+
+// ----------------------------------------------------------------------------
+
+func (e *MalformedError) Error() string {
+	return e.err.Error()
+}
+
+// ----------------------------------------------------------------------------
+
+func (e *MalformedError) Unwrap() error {
+	return e.err
+}
+
+// ----------------------------------------------------------------------------
+
+// Where is the position and reason, like "at byte 12 of the line: invalid
+// character 'x' after object key", for adding to the error's message.
+func (e *MalformedError) Where() string {
+	return fmt.Sprintf("at byte %d of the line: %s", e.Offset, e.Reason)
+}
+
+// ----------------------------------------------------------------------------
+
+// EmptyField finds a required field that is present but empty or only
+// whitespace.  record.Validate reports an empty field the same as a missing
+// one and lets whitespace through, so these are tallied separately.
+func (id Identity) EmptyField(requireRecordId bool) string {
+	if id.DataSource != nil && len(strings.TrimSpace(*id.DataSource)) == 0 {
+		return "DATA_SOURCE"
+	}
+	if id.RecordId != nil && len(strings.TrimSpace(*id.RecordId)) == 0 && requireRecordId {
+		return "RECORD_ID"
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+
+// ValidateRecord validates a line like record.Validate and parses its
+// identity.  The line is decoded once, from its bytes, and only a line that
+// fails to decode is handed to record.Validate for its error, which is then a
+// MalformedError when the decoding error tells where it failed.  The record
+// package always requires a RECORD_ID, so that error is dropped unless
+// requireRecordId is set, as for version 2 of the specification.
+func ValidateRecord(line []byte, requireRecordId bool) (bool, Identity, error) {
+	var fields recordFields
+	var valid bool
+	var err error
+	if decodeErr := json.Unmarshal(line, &fields); decodeErr != nil {
+		fields = recordFields{}
+		valid, err = record.Validate(string(line))
+		if offset, reason, found := decodeFailure(decodeErr); found && err != nil {
+			err = &MalformedError{err: err, Offset: offset, Reason: reason}
+		}
+	} else {
+		valid, err = record.ValidateRecord(record.Record{DataSource: FieldValue(fields.DataSource), Id: FieldValue(fields.RecordId)})
+	}
+	if !valid && !requireRecordId && err != nil && ClassifyError(err) == CategoryNoRecordId {
+		return true, fields.Identity, nil
+	}
+	return valid, fields.Identity, err
+}
+
+// ----------------------------------------------------------------------------
+
+// ClassifyError maps a record.Validate error to a category by the id of its
+// message, which stays the same when the record package rewords it.  A
+// wrapped error is classified by the record package error it wraps.  An
+// error without a known id is a badRecord.
+func ClassifyError(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var message struct {
+			Id string `json:"id"`
+		}
+		if json.Unmarshal([]byte(err.Error()), &message) != nil {
+			continue
+		}
+		for number, category := range recordErrorCategories {
+			if message.Id == fmt.Sprintf("senzing-%04d%04d", record.ProductId, number) {
+				return category
+			}
+		}
+	}
+	return CategoryBadRecord
+}
+
+// ----------------------------------------------------------------------------
+
+// MessageText is the text of an Outcome message for people: a record package
+// error is a JSON log line, only its text is kept, along with whatever follows
+// it, like where a malformed line fails.  Other messages are returned as is.
+func MessageText(message string) string {
+	if !strings.HasPrefix(message, "{") {
+		return message
+	}
+	decoder := json.NewDecoder(strings.NewReader(message))
+	var logged struct {
+		Text string `json:"text"`
+	}
+	if decoder.Decode(&logged) != nil || len(logged.Text) == 0 {
+		return message
+	}
+	return logged.Text + message[decoder.InputOffset():]
+}
+
+// ----------------------------------------------------------------------------
+
+// The byte a json.Unmarshal error happened at and why, when it tells.  A
+// value of the wrong type is described without the Go types decoded into.
+func decodeFailure(err error) (int64, string, bool) {
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		return syntaxError.Offset, syntaxError.Error(), true
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		if len(typeError.Field) == 0 {
+			return typeError.Offset, "the line is a JSON " + typeError.Value + ", not an object", true
+		}
+		return typeError.Offset, typeError.Field + " is a JSON " + typeError.Value + ", not a string", true
+	}
+	return 0, "", false
+}
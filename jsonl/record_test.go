@@ -0,0 +1,92 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/senzing/go-common/record"
+)
+
+// ----------------------------------------------------------------------------
+
+// A record package error with the message of the given number.
+func recordError(number int) error {
+	return fmt.Errorf(`{"id":"senzing-%04d%04d","text":"reworded"}`, record.ProductId, number)
+}
+
+// ----------------------------------------------------------------------------
+
+// Each record package message id maps to its category, whatever the text.
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not well formed", recordError(3000), CategoryMalformed},
+		{"no DATA_SOURCE", recordError(3001), CategoryNoDataSource},
+		{"no RECORD_ID", recordError(3002), CategoryNoRecordId},
+		{"wrapped", fmt.Errorf("reading line 2: %w", recordError(3001)), CategoryNoDataSource},
+		{"malformed error", &MalformedError{err: recordError(3000), Offset: 1, Reason: "invalid character"}, CategoryMalformed},
+		{"unknown id", recordError(3999), CategoryBadRecord},
+		{"another product", errors.New(`{"id":"senzing-99993000"}`), CategoryBadRecord},
+		{"not JSON", errors.New("something else went wrong"), CategoryBadRecord},
+	}
+	for _, test := range tests {
+		if got := ClassifyError(test.err); got != test.want {
+			t.Errorf("%s: ClassifyError(%q) = %s, want %s", test.name, test.err, got, test.want)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The errors record.Validate returns today still map to their categories,
+// so a change to go-common's messages can't reclassify them unnoticed.
+func TestClassifyRecordValidateErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`{"DATA_SOURCE":"TEST",`, CategoryMalformed},
+		{`[]`, CategoryMalformed},
+		{`{"RECORD_ID":"1"}`, CategoryNoDataSource},
+		{`{"DATA_SOURCE":"TEST"}`, CategoryNoRecordId},
+	}
+	for _, test := range tests {
+		valid, err := record.Validate(test.line)
+		if valid || err == nil {
+			t.Errorf("record.Validate(%s) passed, want %s", test.line, test.want)
+			continue
+		}
+		if got := ClassifyError(err); got != test.want {
+			t.Errorf("record.Validate(%s) error %q is %s, want %s", test.line, err, got, test.want)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// The text of a record package error is kept without the rest of its log
+// line, with what follows it, other messages are left alone.
+func TestMessageText(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{recordError(3002).Error(), "reworded"},
+		{recordError(3000).Error() + ", at byte 22 of the line", "reworded, at byte 22 of the line"},
+		{"has an empty RECORD_ID field", "has an empty RECORD_ID field"},
+		{`{"id":"senzing-99993000"}`, `{"id":"senzing-99993000"}`},
+		{`{"text":`, `{"text":`},
+	}
+	for _, test := range tests {
+		if got := MessageText(test.message); got != test.want {
+			t.Errorf("MessageText(%q) = %q, want %q", test.message, got, test.want)
+		}
+	}
+}
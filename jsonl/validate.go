@@ -0,0 +1,120 @@
+/*
+Copyright © 2022  Ron Lynn <dad@lynntribe.net>
+*/
+package jsonl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// The longest line read when Options.MaxLineBytes isn't set, the default of
+// --max-line-bytes.
+const DefaultMaxLineBytes = 16777216
+
+// Options choose the checks of ValidateReader, the zero value checks that
+// every line is a record with a DATA_SOURCE and a RECORD_ID.
+type Options struct {
+	// lines longer than this are lineTooLong and not read, 0 for the default
+	MaxLineBytes int
+	// RECORD_ID is optional, as in version 2 of the specification
+	OptionalRecordId bool
+	// the DATA_SOURCE codes allowed, compared case-insensitively, any when empty
+	AllowedDataSources []string
+	// top-level keys, or keys separated by | of which any one will do
+	RequiredFields []string
+	// check that the records of each DATA_SOURCE are contiguous
+	RequireGroupedDataSource bool
+	// check that the text fields are NFC-normalized, all of them unless the
+	// NormalizedFields are given
+	RequireNormalized bool
+	NormalizedFields  []string
+	// check for a RECORD_ID used twice in a DATA_SOURCE
+	CheckDuplicates bool
+	// stop after this many bad lines, 0 reads the whole stream
+	MaxErrors int
+	// the line numbers of up to this many bad lines are kept per category
+	ExamplesPerCategory int
+	// called with each bad line, in line order, when set
+	BadLine func(lineNumber int, category string, message string)
+	// checks of the caller's own, each skipped when nil: the reason a record
+	// doesn't conform to a schema, and the names of its attributes or
+	// top-level keys that aren't known
+	Schema          func(line []byte) error
+	UnknownFeatures func(fields map[string]interface{}) []string
+	UnknownKeys     func(fields map[string]interface{}) []string
+	// top-level keys left out of the fields given to UnknownFeatures and
+	// UnknownKeys, benign metadata like an _ingest_id
+	IgnoreFields []string
+}
+
+// Result is the outcome of ValidateReader, with the counts the validate
+// command reports.
+type Result struct {
+	Counts
+	Bad          int              `json:"bad"`
+	Valid        bool             `json:"valid"`
+	StoppedEarly bool             `json:"stoppedEarly,omitempty"`
+	Examples     map[string][]int `json:"examples,omitempty"`
+}
+
+// ----------------------------------------------------------------------------
+
+// ValidateReader validates each line of reader as a record, skipping blank
+// lines.  The error is that of reading the stream, the Result then has the
+// lines read before it and isn't Valid.
+func ValidateReader(reader io.Reader, options Options) (Result, error) {
+	if options.MaxLineBytes <= 0 {
+		options.MaxLineBytes = DefaultMaxLineBytes
+	}
+	checker := NewChecker(options)
+	result := Result{}
+	scanner, splitter := NewScanner(reader, options.MaxLineBytes)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		category, message := CategoryLineTooLong, fmt.Sprintf("is longer than %d bytes", options.MaxLineBytes)
+		if !splitter.TooLong {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				result.BlankLines++
+				continue
+			}
+			outcome := checker.CheckRecord(line)
+			checker.CheckOrder(&outcome, lineNumber)
+			category, message = outcome.Category, outcome.Message
+		}
+		result.TotalLines++
+		if len(category) == 0 {
+			continue
+		}
+		result.add(category, lineNumber, options.ExamplesPerCategory)
+		if options.BadLine != nil {
+			options.BadLine(lineNumber, category, message)
+		}
+		if options.MaxErrors > 0 && result.BadLines() >= options.MaxErrors {
+			result.StoppedEarly = true
+			break
+		}
+	}
+	err := scanner.Err()
+	result.Bad = result.BadLines()
+	result.Valid = result.Bad == 0 && err == nil
+	return result, err
+}
+
+// ----------------------------------------------------------------------------
+
+// Count a bad line, keeping the first line numbers of each category as
+// examples.
+func (r *Result) add(category string, lineNumber int, examplesPerCategory int) {
+	r.Counts.Add(category)
+	if examplesPerCategory <= 0 {
+		return
+	}
+	if r.Examples == nil {
+		r.Examples = map[string][]int{}
+	}
+	if len(r.Examples[category]) < examplesPerCategory {
+		r.Examples[category] = append(r.Examples[category], lineNumber)
+	}
+}
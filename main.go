@@ -6,6 +6,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/roncewind/validate/cmd"
 )
@@ -13,5 +14,5 @@ import (
 func main() {
 	log.SetFlags(log.Llongfile | log.Ldate | log.Lmicroseconds | log.LUTC)
 
-	cmd.Execute()
+	os.Exit(cmd.Execute())
 }